@@ -129,9 +129,10 @@ func (c *ListCmd) Run() error {
 }
 
 func main() {
-	app := &CLI{}
-	if err := cli.Run(app); err != nil {
+	app, err := cli.New(&CLI{})
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	app.Main()
 }
@@ -3,21 +3,43 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
 	"maps"
 	"os"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/mirkobrombin/go-cli-builder/v2/internal/binder"
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/config"
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/dotenv"
 	"github.com/mirkobrombin/go-cli-builder/v2/pkg/help"
 	"github.com/mirkobrombin/go-cli-builder/v2/pkg/log"
 	"github.com/mirkobrombin/go-cli-builder/v2/pkg/parser"
 	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/respfile"
+	"github.com/mirkobrombin/go-foundation/pkg/options"
 )
 
+const (
+	// debugFlagsName is the built-in flag printing the resolved flag values and their origin.
+	debugFlagsName = "debug-flags"
+	// configFlagName is the built-in flag loading an explicit configuration file.
+	configFlagName = "config"
+)
+
+// bindOptions configures how applyBindings resolves flag values.
+type bindOptions struct {
+	providers    []resolver.Provider          // Sources queried after the command line
+	expand       map[resolver.SourceKind]bool // Sources whose values are expanded, nil disables expansion
+	env          resolver.EnvLookup           // Variables read by expansion, nil for the process environment
+	stdin        io.Reader                    // Read by "-" values
+	maxValueSize int64                        // Limit of values read from files and stdin
+}
+
 // applyBindings binds flags and args to the struct fields using the external binder library.
-func applyBindings(node *parser.CommandNode, flags map[string]string, args []string, effectiveFlags map[string]*parser.FlagMetadata) error {
+func applyBindings(node *parser.CommandNode, flags map[string][]string, args, rest []string, effectiveFlags map[string]*parser.FlagMetadata, opts bindOptions) error {
 	val := node.Value
 	if val.Kind() != reflect.Ptr && val.CanAddr() {
 		val = val.Addr()
@@ -28,86 +50,208 @@ func applyBindings(node *parser.CommandNode, flags map[string]string, args []str
 		return err
 	}
 
+	// Fields start from their initial value so that nothing bound by a
+	// previous run leaks into this one. Pointer fields are bound to a fresh
+	// value which is only assigned when a source provides one.
+	targets := make(map[string]reflect.Value, len(effectiveFlags))
 	for name, meta := range effectiveFlags {
-		m := meta
-
-		switch m.Field.Kind() {
-		case reflect.Bool:
-			b.AddBool(name, func(v bool) error {
-				m.Field.SetBool(v)
-				return nil
-			})
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			if m.Field.Type() == reflect.TypeOf(time.Duration(0)) {
-				b.AddDuration(name, func(v time.Duration) error {
-					m.Field.SetInt(int64(v))
-					return nil
-				})
-			} else {
-				b.AddInt(name, func(v int64) error {
-					m.Field.SetInt(v)
-					return nil
-				})
-			}
-		case reflect.String:
-			b.AddStrings(name, func(v []string) error {
-				if len(v) > 0 {
-					m.Field.SetString(v[0])
-				}
-				return nil
-			})
-		default:
-			if m.Field.Kind() == reflect.Slice && m.Field.Type().Elem().Kind() == reflect.String {
-				b.AddStrings(name, func(v []string) error {
-					s := reflect.MakeSlice(m.Field.Type(), len(v), len(v))
-					for i, val := range v {
-						s.Index(i).SetString(val)
-					}
-					m.Field.Set(s)
-					return nil
-				})
-			}
+		meta.Reset()
+		field := meta.Field
+		if field.Kind() == reflect.Ptr {
+			field = reflect.New(field.Type().Elem()).Elem()
 		}
+		targets[name] = field
+		addHandler(b, name, field, meta)
 	}
 
+	input := &inputReader{stdin: opts.stdin, maxSize: opts.maxValueSize}
+	resolved := make(map[string]*resolvedFlag, len(effectiveFlags))
 	for name, meta := range effectiveFlags {
-		var passedVal *string
-		if v, ok := flags[name]; ok {
-			passedVal = &v
+		passed := resolver.SplitValues(flags[name], meta.Separator)
+
+		// Env and default values can't be repeated, so multi-value flags
+		// read them as a list.
+		listSep := ""
+		if kind := meta.ValueType().Kind(); (kind == reflect.Slice || kind == reflect.Map) && !resolver.IsCustom(meta.ValueType()) {
+			listSep = meta.Separator
+			if listSep == "" {
+				listSep = ","
+			}
 		}
 
-		valToBind, err := resolver.GetValue(passedVal, meta.Env, meta.Default, meta.Field.Kind() == reflect.Bool)
+		values, origin, err := resolver.Resolve(resolver.Query{
+			Flag:      name,
+			CLI:       passed,
+			Env:       meta.Env,
+			ConfigKey: meta.ConfigKey,
+			Default:   meta.Default,
+			Sep:       listSep,
+		}, opts.providers...)
 		if err != nil {
+			return fmt.Errorf("flag --%s: %w", name, err)
+		}
+		if origin.Source == resolver.SourceFlag {
+			values, err = input.readValues(meta, values, &origin)
+			if err != nil {
+				return fmt.Errorf("flag --%s: %w", name, err)
+			}
+		}
+		resolved[name] = &resolvedFlag{values: values, origin: origin}
+	}
+
+	if opts.expand != nil {
+		if err := expandValues(resolved, effectiveFlags, opts.expand, opts.env); err != nil {
 			return err
 		}
+	}
 
-		if meta.Required && valToBind == "" && meta.Field.Kind() != reflect.Bool {
+	for name, meta := range effectiveFlags {
+		values, origin := resolved[name].values, resolved[name].origin
+
+		// An empty value leaves the flag unset, unless it was given on the
+		// command line to a field able to hold it: --name= sets a *string
+		// to "" and --tags= clears a slice.
+		empty := len(values) == 0 || (len(values) == 1 && values[0] == "")
+		set := origin.Source != resolver.SourceNone && !empty
+		if empty && origin.Source == resolver.SourceFlag && holdsEmpty(meta) {
+			set = true
+			if kind := meta.ValueType().Kind(); kind == reflect.Slice || kind == reflect.Map {
+				values = nil
+			}
+		}
+		if !set {
+			origin = resolver.Origin{}
+		}
+		meta.Origin = origin
+
+		if meta.Required && (!set || empty) && meta.ValueType().Kind() != reflect.Bool {
 			return fmt.Errorf("missing required flag: --%s", name)
 		}
 
-		if valToBind != "" {
-			if err := b.Run(name, []string{valToBind}); err != nil {
+		if set {
+			if err := b.Run(name, values); err != nil {
+				if meta.Secret {
+					err = maskSecret(err, values)
+				}
+				if origin.Source == resolver.SourceConfig {
+					return fmt.Errorf("%s:%d: invalid value for flag --%s (%s): %w", origin.File, origin.Line, name, origin.Key, err)
+				}
 				return fmt.Errorf("invalid value for flag --%s: %w", name, err)
 			}
+			if meta.Field.Kind() == reflect.Ptr {
+				meta.Field.Set(targets[name].Addr())
+			}
 		}
 	}
 
-	return bindArgs(node, args)
+	return bindArgs(node, args, rest)
+}
+
+// holdsEmpty reports whether the field of a flag can hold an empty value:
+// strings, slices and maps.
+func holdsEmpty(meta *parser.FlagMetadata) bool {
+	typ := meta.ValueType()
+	if resolver.IsCustom(typ) {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// addHandler registers the binder handler converting raw values for the given field.
+func addHandler(b *binder.Binder, name string, field reflect.Value, meta *parser.FlagMetadata) {
+	if resolver.IsCustom(field.Type()) {
+		b.AddStrings(name, func(v []string) error {
+			for _, item := range v {
+				if err := resolver.BindValue(field, item); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return
+	}
+
+	switch field.Kind() {
+	case reflect.Slice:
+		b.AddStrings(name, func(v []string) error {
+			return resolver.BindSlice(field, v)
+		})
+	case reflect.Map:
+		b.AddStrings(name, func(v []string) error {
+			mp := reflect.MakeMapWithSize(field.Type(), len(v))
+			for _, item := range v {
+				key, elem, err := resolver.ParseMapEntry(field.Type(), item)
+				if err != nil {
+					return err
+				}
+				if mp.MapIndex(key).IsValid() {
+					switch meta.Duplicates {
+					case "error":
+						return fmt.Errorf("duplicate key: %v", key)
+					case "first":
+						continue
+					}
+				}
+				mp.SetMapIndex(key, elem)
+			}
+			field.Set(mp)
+			return nil
+		})
+	default:
+		// Scalars keep the value of their last occurrence and are converted
+		// like positional arguments.
+		b.AddStrings(name, func(v []string) error {
+			if len(v) == 0 {
+				return nil
+			}
+			return resolver.BindValue(field, v[len(v)-1])
+		})
+	}
 }
 
 // App represents a CLI application.
 type App struct {
 	RootNode   *parser.CommandNode
 	Translator help.Translator
+
+	// Stdin, Stdout and Stderr are the streams of the application, injected
+	// into commands embedding Base. They default to the process streams.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	path          []*parser.CommandNode
+	configEnabled bool
+	configPaths   []string
+	dotenvPaths   []string
+	envPrefix     string
+	env           resolver.EnvLookup
+	sources       []resolver.Provider
+	expand        map[resolver.SourceKind]bool
+	maxValueSize  int64
+	responseFiles bool
+	signals       bool // Handle signals, see WithSignalHandling
+	signalsSet    bool // Whether signals was set explicitly
+	middleware    []Middleware
 }
 
 // New creates a new App from a root struct.
-func New(root any) (*App, error) {
+//
+// Example:
+//
+//	app, err := cli.New(&CLI{}, cli.WithConfig("mytool"))
+func New(root any, opts ...Option) (*App, error) {
 	rootNode, err := parser.Parse("root", root)
 	if err != nil {
 		return nil, fmt.Errorf("parse error: %w", err)
 	}
-	return &App{RootNode: rootNode}, nil
+	app := &App{RootNode: rootNode}
+	options.Apply(app, opts...)
+	return app, nil
 }
 
 // SetName sets the name of the root command.
@@ -118,6 +262,7 @@ func (a *App) SetName(name string) {
 }
 
 // Reload re-parses the root struct to pick up dynamic changes (e.g. map entries).
+// The current field values become the initial ones, restored before every run.
 //
 // Example:
 //
@@ -141,6 +286,41 @@ func (a *App) AddCommand(name string, cmd *parser.CommandNode) {
 	a.RootNode.Children[name] = cmd
 }
 
+// AddSource appends value sources to the resolution chain. Sources are queried
+// in order after environment variables and before configuration files.
+//
+// Example:
+//
+//	app.AddSource(resolver.MapSource{"API_TOKEN": "test"})
+func (a *App) AddSource(sources ...Source) {
+	a.sources = append(a.sources, sources...)
+}
+
+// FlagOrigin returns where the value of the named flag came from in the last run.
+// Flags of the executed command shadow the ones of its parents.
+//
+// Example:
+//
+//	origin, ok := app.FlagOrigin("port")
+func (a *App) FlagOrigin(name string) (resolver.Origin, bool) {
+	for i := len(a.path) - 1; i >= 0; i-- {
+		if origin, ok := a.path[i].FlagOrigin(name); ok {
+			return origin, true
+		}
+	}
+	return resolver.Origin{}, false
+}
+
+// Changed reports whether the named flag was explicitly passed on the command line in the last run.
+//
+// Example:
+//
+//	if app.Changed("port") { ... }
+func (a *App) Changed(name string) bool {
+	origin, ok := a.FlagOrigin(name)
+	return ok && origin.Source == resolver.SourceFlag
+}
+
 // SetTranslator sets the translator for the application.
 func (a *App) SetTranslator(tr help.Translator) {
 	a.Translator = tr
@@ -157,104 +337,334 @@ func (a *App) SetTranslator(tr help.Translator) {
 //			log.Fatal(err)
 //		}
 //	}
-func Run(root any) error {
-	app, err := New(root)
+func Run(root any, opts ...Option) error {
+	app, err := New(root, opts...)
 	if err != nil {
 		return err
 	}
 	return app.Run()
 }
 
-// Run executes the application.
+// Run executes the application with the arguments of the process. Commands
+// embedding Base receive a context cancelled when the application receives
+// SIGINT or SIGTERM, unless signal handling is disabled with
+// WithSignalHandling(false).
 func (a *App) Run() error {
-	args := os.Args[1:]
+	return a.run(context.Background(), os.Args[1:], a.signals || !a.signalsSet)
+}
+
+// RunWithArgs executes the application with the given arguments, excluding the
+// program name. Commands embedding Base receive a context derived from ctx.
+// Signals are left to the caller unless handling them is enabled with
+// WithSignalHandling(true), so that tests can run invocations in parallel. It
+// can be called many times: flags and arguments not given by a run get back
+// the value they had when the struct was parsed.
+//
+// Example:
+//
+//	var out bytes.Buffer
+//	app.Stdout = &out
+//	err := app.RunWithArgs(ctx, []string{"deploy", "--region", "eu"})
+func (a *App) RunWithArgs(ctx context.Context, args []string) error {
+	return a.run(ctx, args, a.signals)
+}
+
+// run executes the application with the given arguments, cancelling the
+// context of the commands on SIGINT and SIGTERM when handleSignals is set.
+func (a *App) run(ctx context.Context, args []string, handleSignals bool) error {
+	stdin, stdout, stderr := a.streams()
+
+	if handleSignals {
+		var stop func()
+		ctx, stop = signalContext(ctx, stderr)
+		defer stop()
+	}
+
+	if a.responseFiles {
+		expanded, err := respfile.ExpandFunc(args, a.responseFileMode())
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return &UsageError{Err: err}
+		}
+		args = expanded
+	}
 
 	targetNode, allFlags, err := resolveCommand(a.RootNode, args)
 	if err != nil {
-		fmt.Println(help.GenerateHelp(a.RootNode, a.Translator))
-		return err
+		fmt.Fprintln(stderr, a.helpText(a.RootNode))
+		return &UsageError{Err: err}
 	}
 
 	path := getPathToNode(a.RootNode, targetNode)
+	a.path = path
 	effectiveFlags := make(map[string]*parser.FlagMetadata)
 
 	for _, node := range path {
 		maps.Copy(effectiveFlags, node.Flags)
 	}
 
+	if a.envPrefix != "" {
+		assignEnvNames(path, a.envPrefix)
+	}
+
 	for _, arg := range allFlags {
+		if arg == "--" {
+			break
+		}
 		if arg == "-h" || arg == "--help" {
-			fmt.Print(help.GenerateHelp(targetNode, a.Translator))
+			fmt.Fprint(stdout, a.helpText(targetNode))
 			return nil
 		}
 	}
 
-	parsedFlags, positionalArgs, err := parseArgs(allFlags, effectiveFlags)
+	allFlags, _, debugFlags, err := takeBuiltinFlag(allFlags, debugFlagsName, false, effectiveFlags)
 	if err != nil {
-		fmt.Printf("Error: %v\n\n", err)
-		fmt.Print(help.GenerateHelp(targetNode, a.Translator))
-		return err
+		fmt.Fprintf(stderr, "Error: %v\n\n", err)
+		fmt.Fprint(stderr, a.helpText(targetNode))
+		return &UsageError{Err: err}
 	}
 
-	if err := applyBindings(targetNode, parsedFlags, positionalArgs, effectiveFlags); err != nil {
-		fmt.Printf("Error: %v\n\n", err)
-		fmt.Print(help.GenerateHelp(targetNode, a.Translator))
-		return err
+	env := a.env
+	if a.dotenvPaths != nil {
+		loaded, err := dotenv.LoadWith(a.env, a.dotenvPaths...)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return &UsageError{Err: err}
+		}
+		env = loaded
 	}
 
-	for _, node := range path {
-		injectDependencies(node)
+	var cfg resolver.ConfigLookup
+	if a.configEnabled {
+		var configPath string
+		var hasConfigPath bool
+		allFlags, configPath, hasConfigPath, err = takeBuiltinFlag(allFlags, configFlagName, true, effectiveFlags)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n\n", err)
+			fmt.Fprint(stderr, a.helpText(targetNode))
+			return &UsageError{Err: err}
+		}
+
+		loaded, err := a.loadConfig(configPath, hasConfigPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return &UsageError{Err: err}
+		}
+		cfg = loaded
 	}
 
+	// Keys are assigned regardless of configuration files, custom sources may use them.
+	assignConfigKeys(path)
+
+	allFlags, secretFiles, err := takeSecretFiles(allFlags, effectiveFlags)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n\n", err)
+		fmt.Fprint(stderr, a.helpText(targetNode))
+		return &UsageError{Err: err}
+	}
+
+	maxValueSize := a.maxValueSize
+	if maxValueSize <= 0 {
+		maxValueSize = defaultMaxValueSize
+	}
+
+	providers := append([]resolver.Provider{secretFileProvider(secretFiles, maxValueSize), resolver.EnvProvider(env)}, a.sources...)
+	if cfg != nil {
+		providers = append(providers, resolver.ConfigProvider(cfg))
+	}
+
+	parsedFlags, positionalArgs, passthrough, err := parseArgs(allFlags, effectiveFlags)
+	if err == nil {
+		err = checkSecretFiles(parsedFlags, secretFiles)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n\n", err)
+		fmt.Fprint(stderr, a.helpText(targetNode))
+		return &UsageError{Err: err}
+	}
+	warnSecretFlags(parsedFlags, effectiveFlags, log.NewWithWriter(stderr))
+
+	if err := applyBindings(targetNode, parsedFlags, positionalArgs, passthrough, effectiveFlags, bindOptions{
+		providers:    providers,
+		expand:       a.expand,
+		env:          env,
+		stdin:        stdin,
+		maxValueSize: maxValueSize,
+	}); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n\n", err)
+		fmt.Fprint(stderr, a.helpText(targetNode))
+		return &UsageError{Err: err}
+	}
+
+	if debugFlags {
+		fmt.Fprint(stdout, help.GenerateFlagReport(effectiveFlags))
+		return nil
+	}
+
+	inv := &Invocation{
+		Args:       maskArgs(args, parsedFlags, effectiveFlags),
+		Nodes:      path,
+		Command:    commandValue(targetNode),
+		Positional: positionalArgs,
+		Rest:       passthrough,
+		Sources:    make(map[string]resolver.Origin, len(effectiveFlags)),
+	}
 	for _, node := range path {
-		if beforeRunner, ok := node.Value.Interface().(BeforeRunner); ok {
-			if err := beforeRunner.Before(); err != nil {
-				return err
+		inv.Path = append(inv.Path, node.Name)
+	}
+	for name, meta := range effectiveFlags {
+		inv.Sources[name] = meta.Origin
+	}
+
+	handler := a.chain(func(ctx context.Context, inv *Invocation) error {
+		// Middleware may replace the context, so the invocation is attached
+		// to the one it passes down rather than to the original.
+		ctx = withInvocation(ctx, inv)
+
+		// Base is injected here to carry the context passed down by the middleware.
+		base := Base{
+			Logger: log.Redact(log.NewWithWriter(stdout), resolver.SecretMask, secretValues(effectiveFlags)...),
+			Ctx:    ctx,
+			Stdin:  stdin,
+			Stdout: stdout,
+			Stderr: stderr,
+		}
+		nodeFlags := make(map[string]*parser.FlagMetadata)
+		for _, node := range path {
+			maps.Copy(nodeFlags, node.Flags)
+			base.flags = maps.Clone(nodeFlags)
+			injectDependencies(node, base)
+		}
+
+		return runLifecycle(ctx, path, func() error {
+			executed, err := runCommand(ctx, targetNode)
+			if !executed {
+				fmt.Fprint(stdout, a.helpText(targetNode))
 			}
-		} else if node.Value.CanAddr() {
-			if beforeRunner, ok := node.Value.Addr().Interface().(BeforeRunner); ok {
-				if err := beforeRunner.Before(); err != nil {
-					return err
-				}
+			return err
+		})
+	}, path)
+
+	return handler(ctx, inv)
+}
+
+// helpText returns the help of a command node, listing the built-in flags not
+// shadowed by a flag of the command path.
+func (a *App) helpText(node *parser.CommandNode) string {
+	defined := func(name string) bool {
+		for _, n := range getPathToNode(a.RootNode, node) {
+			if _, ok := n.Flags[name]; ok {
+				return true
 			}
 		}
+		return false
 	}
 
-	executed := false
-	if runner, ok := targetNode.Value.Interface().(Runner); ok {
-		if err := runner.Run(); err != nil {
-			return err
-		}
-		executed = true
-	} else if targetNode.Value.CanAddr() {
-		if runner, ok := targetNode.Value.Addr().Interface().(Runner); ok {
-			if err := runner.Run(); err != nil {
-				return err
+	var builtins []help.Builtin
+	if !defined(debugFlagsName) {
+		builtins = append(builtins, help.Builtin{
+			Name:        debugFlagsName,
+			Description: "Print the resolved flag values and their source",
+		})
+	}
+	if a.configEnabled && !defined(configFlagName) {
+		builtins = append(builtins, help.Builtin{
+			Name:        configFlagName,
+			Placeholder: "file",
+			Description: "Load configuration from file",
+		})
+	}
+	return help.GenerateHelp(node, a.Translator, builtins...)
+}
+
+// responseFileMode tells how the argument following a flag is expanded as a
+// response file. As the command is not resolved yet, the flags of every
+// command are considered and the strictest mode wins: the value of a flag
+// reading @path itself is kept, and a response file given as the value of
+// another flag is rejected, since its content would be split.
+func (a *App) responseFileMode() func(string) respfile.ValueMode {
+	long := make(map[string]respfile.ValueMode)
+	short := make(map[string]respfile.ValueMode)
+	set := func(m map[string]respfile.ValueMode, name string, mode respfile.ValueMode) {
+		m[name] = max(m[name], mode)
+	}
+
+	var walk func(node *parser.CommandNode)
+	walk = func(node *parser.CommandNode) {
+		for name, meta := range node.Flags {
+			mode := respfile.ExpandNext
+			switch {
+			case meta.FromFile:
+				mode = respfile.KeepNext
+			case meta.TakesValue():
+				mode = respfile.RejectNext
+			}
+			set(long, name, mode)
+			if meta.Short != "" {
+				set(short, meta.Short, mode)
 			}
-			executed = true
+			if meta.Secret {
+				set(long, name+secretFileSuffix, respfile.RejectNext)
+			}
+		}
+		for _, child := range node.Children {
+			walk(child)
 		}
 	}
-
-	if !executed {
-		fmt.Print(help.GenerateHelp(targetNode, a.Translator))
+	walk(a.RootNode)
+	if a.configEnabled {
+		set(long, configFlagName, respfile.RejectNext)
 	}
 
-	for i := len(path) - 1; i >= 0; i-- {
-		node := path[i]
-		if afterRunner, ok := node.Value.Interface().(AfterRunner); ok {
-			if err := afterRunner.After(); err != nil {
-				return err
+	return func(arg string) respfile.ValueMode {
+		if strings.Contains(arg, "=") {
+			return respfile.ExpandNext
+		}
+		if name, ok := strings.CutPrefix(arg, "--"); ok {
+			return long[name]
+		}
+		body, ok := strings.CutPrefix(arg, "-")
+		if !ok || body == "" {
+			return respfile.ExpandNext
+		}
+		if mode, ok := short[body]; ok {
+			return mode
+		}
+		if mode, ok := long[body]; ok {
+			return mode
+		}
+		// Like parseShorthands, the first flag taking a value in a group
+		// takes the rest of it, or the next argument when it closes it.
+		for j, r := range body {
+			flag := string(r)
+			mode, ok := short[flag]
+			if !ok {
+				return respfile.ExpandNext
 			}
-		} else if node.Value.CanAddr() {
-			if afterRunner, ok := node.Value.Addr().Interface().(AfterRunner); ok {
-				if err := afterRunner.After(); err != nil {
-					return err
+			if mode != respfile.ExpandNext {
+				if j+len(flag) < len(body) {
+					return respfile.ExpandNext
 				}
+				return mode
 			}
 		}
+		return respfile.ExpandNext
 	}
+}
 
-	return nil
+// streams returns the streams of the application, defaulting to the process ones.
+func (a *App) streams() (io.Reader, io.Writer, io.Writer) {
+	stdin, stdout, stderr := a.Stdin, a.Stdout, a.Stderr
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	return stdin, stdout, stderr
 }
 
 // resolveCommand traverses the tree, skipping flags to find subcommands.
@@ -266,6 +676,12 @@ func resolveCommand(root *parser.CommandNode, args []string) (*parser.CommandNod
 	for i := range args {
 		arg := args[i]
 
+		// Everything after the terminator belongs to the resolved command.
+		if arg == "--" {
+			remaining = append(remaining, args[i:]...)
+			break
+		}
+
 		if parsingCmds {
 			if strings.HasPrefix(arg, "-") {
 				remaining = append(remaining, arg)
@@ -286,6 +702,103 @@ func resolveCommand(root *parser.CommandNode, args []string) (*parser.CommandNod
 	return current, remaining, nil
 }
 
+// takeBuiltinFlag removes a built-in flag from args, unless the command path
+// defines a flag with the same name. It returns the remaining args, the flag
+// value and whether the flag was found.
+func takeBuiltinFlag(args []string, name string, takesValue bool, effectiveFlags map[string]*parser.FlagMetadata) ([]string, string, bool, error) {
+	if _, ok := effectiveFlags[name]; ok {
+		return args, "", false, nil
+	}
+
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+
+		if arg == "--"+name {
+			if !takesValue {
+				return slices.Concat(args[:i], args[i+1:]), "", true, nil
+			}
+			if i+1 >= len(args) {
+				return nil, "", false, fmt.Errorf("flag needs an argument: --%s", name)
+			}
+			return slices.Concat(args[:i], args[i+2:]), args[i+1], true, nil
+		}
+
+		if value, ok := strings.CutPrefix(arg, "--"+name+"="); ok {
+			if !takesValue {
+				return nil, "", false, fmt.Errorf("flag does not take an argument: --%s", name)
+			}
+			return slices.Concat(args[:i], args[i+1:]), value, true, nil
+		}
+	}
+
+	return args, "", false, nil
+}
+
+// loadConfig loads the configuration files from the search paths, followed by
+// the explicit file passed with --config which must exist.
+func (a *App) loadConfig(explicit string, hasExplicit bool) (*config.Config, error) {
+	cfg, err := config.Discover(a.configPaths...)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasExplicit {
+		f, err := config.Load(explicit)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Add(f)
+	}
+
+	return cfg, nil
+}
+
+// assignConfigKeys derives the configuration key of flags without a config tag
+// from the command path and the flag name, e.g. deploy.region.
+func assignConfigKeys(path []*parser.CommandNode) {
+	for i, node := range path {
+		prefix := ""
+		for _, n := range path[1 : i+1] {
+			prefix += n.Name + "."
+		}
+		for name, meta := range node.Flags {
+			if meta.ConfigKey == "" {
+				meta.ConfigKey = prefix + name
+			}
+		}
+	}
+}
+
+// assignEnvNames derives the environment variable of flags without an env tag
+// from the prefix, the command path and the flag name, e.g. MYTOOL_DEPLOY_REGION.
+func assignEnvNames(path []*parser.CommandNode, prefix string) {
+	for i, node := range path {
+		parts := []string{prefix}
+		for _, n := range path[1 : i+1] {
+			parts = append(parts, n.Name)
+		}
+		for name, meta := range node.Flags {
+			if meta.Env == "" {
+				meta.Env = envName(append(parts, name)...)
+			}
+		}
+	}
+}
+
+// envName joins parts into an environment variable name, upper casing them and
+// replacing characters not allowed in names with underscores.
+func envName(parts ...string) string {
+	name := strings.ToUpper(strings.Join(parts, "_"))
+	return strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
 // getPathToNode reconstructs path from root to target (inefficient but safe).
 func getPathToNode(root, target *parser.CommandNode) []*parser.CommandNode {
 	if root == target {
@@ -301,9 +814,11 @@ func getPathToNode(root, target *parser.CommandNode) []*parser.CommandNode {
 }
 
 // parseArgs parses flags based on effective metadata.
-func parseArgs(args []string, effectiveFlags map[string]*parser.FlagMetadata) (map[string]string, []string, error) {
-	flags := make(map[string]string)
+// Arguments following the "--" terminator are returned verbatim as the passthrough tail.
+func parseArgs(args []string, effectiveFlags map[string]*parser.FlagMetadata) (map[string][]string, []string, []string, error) {
+	flags := make(map[string][]string)
 	positionals := []string{}
+	var rest []string
 
 	// Reverse Lookup for short flags
 	shortMap := make(map[string]string)
@@ -317,6 +832,11 @@ func parseArgs(args []string, effectiveFlags map[string]*parser.FlagMetadata) (m
 	for i < len(args) {
 		arg := args[i]
 
+		if arg == "--" {
+			rest = append([]string{}, args[i+1:]...)
+			break
+		}
+
 		if strings.HasPrefix(arg, "--") {
 			name := arg[2:]
 			value := ""
@@ -331,87 +851,184 @@ func parseArgs(args []string, effectiveFlags map[string]*parser.FlagMetadata) (m
 
 			meta, ok := effectiveFlags[name]
 			if !ok {
-				return nil, nil, fmt.Errorf("unknown flag: --%s", name)
+				if negated, ok := negatedFlag(name, effectiveFlags); ok {
+					if hasValue {
+						return nil, nil, nil, fmt.Errorf("flag does not take an argument: --%s", name)
+					}
+					flags[negated] = append(flags[negated], "false")
+					i++
+					continue
+				}
+				return nil, nil, nil, fmt.Errorf("unknown flag: --%s", name)
 			}
 
 			if hasValue {
-				flags[name] = value
+				flags[name] = append(flags[name], value)
 			} else {
-				if meta.Field.Kind() == reflect.Bool {
-					flags[name] = "true"
+				if !meta.TakesValue() {
+					setSwitch(flags, name, meta)
 				} else {
 					if i+1 < len(args) {
-						flags[name] = args[i+1]
+						flags[name] = append(flags[name], args[i+1])
 						i++
 					} else {
-						return nil, nil, fmt.Errorf("flag needs an argument: --%s", name)
+						return nil, nil, nil, fmt.Errorf("flag needs an argument: --%s", name)
 					}
 				}
 			}
 
-		} else if strings.HasPrefix(arg, "-") {
-			shorthand := arg[1:]
-			name := shorthand
-			value := ""
-			hasValue := false
+		} else if strings.HasPrefix(arg, "-") && arg != "-" {
+			consumed, err := parseShorthands(arg[1:], args[i+1:], flags, effectiveFlags, shortMap)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			i += consumed
 
-			if strings.Contains(name, "=") {
-				parts := strings.SplitN(name, "=", 2)
-				sName := parts[0]
-				value = parts[1]
-				hasValue = true
+		} else {
+			positionals = append(positionals, arg)
+		}
+		i++
+	}
 
-				if lName, ok := shortMap[sName]; ok {
-					name = lName
-				} else {
-					return nil, nil, fmt.Errorf("unknown shorthand flag: -%s", sName)
-				}
-			} else {
-				if lName, ok := shortMap[name]; ok {
-					name = lName
-				} else {
-					if _, ok := effectiveFlags[name]; !ok {
-						return nil, nil, fmt.Errorf("unknown shorthand flag: -%s", shorthand)
-					}
-				}
-			}
+	return flags, positionals, rest, nil
+}
 
-			meta := effectiveFlags[name]
+// setSwitch records an occurrence of a flag that takes no value.
+// Counter flags are incremented, boolean flags are set to true.
+func setSwitch(flags map[string][]string, name string, meta *parser.FlagMetadata) {
+	if !meta.Counter {
+		flags[name] = append(flags[name], "true")
+		return
+	}
+	count := 0
+	if prev := flags[name]; len(prev) > 0 {
+		count, _ = strconv.Atoi(prev[len(prev)-1])
+	}
+	flags[name] = []string{strconv.Itoa(count + 1)}
+}
 
-			if hasValue {
-				flags[name] = value
+// negatedFlag resolves a --no-<name> flag to the name of the negatable flag it refers to.
+func negatedFlag(name string, effectiveFlags map[string]*parser.FlagMetadata) (string, bool) {
+	target, ok := strings.CutPrefix(name, "no-")
+	if !ok {
+		return "", false
+	}
+	meta, ok := effectiveFlags[target]
+	if !ok || !meta.Negatable {
+		return "", false
+	}
+	return target, true
+}
+
+// parseShorthands parses a single-dash token. Besides single shorthands (-v, -o=x),
+// it accepts clusters of boolean shorthands (-abc), a value-taking shorthand with
+// an attached value (-ofile, -xzfarchive.tar) and a trailing value-taking
+// shorthand in a cluster (-xzf archive.tar). A value-taking shorthand followed
+// by other shorthands (-xfz) is an error. It returns how many of the next
+// arguments were consumed.
+func parseShorthands(token string, next []string, flags map[string][]string, effectiveFlags map[string]*parser.FlagMetadata, shortMap map[string]string) (int, error) {
+	body, value, hasValue := strings.Cut(token, "=")
+
+	// An exact match keeps multi-character shorthands and -name working.
+	name, ok := shortMap[body]
+	if !ok {
+		if _, ok = effectiveFlags[body]; ok {
+			name = body
+		}
+	}
+	if ok {
+		meta := effectiveFlags[name]
+		switch {
+		case hasValue:
+			flags[name] = append(flags[name], value)
+		case !meta.TakesValue():
+			setSwitch(flags, name, meta)
+		case len(next) > 0:
+			flags[name] = append(flags[name], next[0])
+			return 1, nil
+		default:
+			return 0, fmt.Errorf("flag needs an argument: -%s", body)
+		}
+		return 0, nil
+	}
+
+	for j, r := range body {
+		short := string(r)
+		name, ok := shortMap[short]
+		if !ok {
+			return 0, fmt.Errorf("unknown shorthand flag: -%s in -%s", short, token)
+		}
+
+		meta := effectiveFlags[name]
+		isLast := j+len(short) == len(body)
+
+		if !meta.TakesValue() {
+			if isLast && hasValue {
+				flags[name] = append(flags[name], value)
 			} else {
-				if meta.Field.Kind() == reflect.Bool {
-					flags[name] = "true"
-				} else {
-					if i+1 < len(args) {
-						flags[name] = args[i+1]
-						i++
-					} else {
-						return nil, nil, fmt.Errorf("flag needs an argument: -%s", shorthand)
-					}
-				}
+				setSwitch(flags, name, meta)
 			}
+			continue
+		}
 
-		} else {
-			positionals = append(positionals, arg)
+		if !isLast {
+			// -ofile, -xzfarchive.tar: the rest of the token is the value,
+			// unless it is made of shorthands, as in -xfz.
+			if j > 0 && allShorthands(body[j+len(short):], shortMap) {
+				return 0, fmt.Errorf("shorthand flag -%s in -%s needs an argument and must be the last of the group", short, token)
+			}
+			flags[name] = append(flags[name], token[j+len(short):])
+			return 0, nil
 		}
-		i++
+
+		if hasValue {
+			flags[name] = append(flags[name], value)
+			return 0, nil
+		}
+		if len(next) > 0 {
+			flags[name] = append(flags[name], next[0])
+			return 1, nil
+		}
+		return 0, fmt.Errorf("flag needs an argument: -%s", short)
 	}
 
-	return flags, positionals, nil
+	return 0, nil
+}
+
+// allShorthands reports whether every character of s is a known shorthand.
+func allShorthands(s string, shortMap map[string]string) bool {
+	for _, r := range s {
+		if _, ok := shortMap[string(r)]; !ok {
+			return false
+		}
+	}
+	return true
 }
 
 // bindArgs binds positional arguments to the struct fields using the internal resolver.
-func bindArgs(node *parser.CommandNode, args []string) error {
+// The passthrough tail is captured by the node's passthrough field when declared,
+// otherwise it is treated as regular positional arguments.
+func bindArgs(node *parser.CommandNode, args, rest []string) error {
+	for _, meta := range node.Args {
+		meta.Reset()
+	}
+
+	if node.Passthrough != nil {
+		s := reflect.MakeSlice(node.Passthrough.Field.Type(), 0, len(rest))
+		for _, v := range rest {
+			s = reflect.Append(s, reflect.ValueOf(v))
+		}
+		node.Passthrough.Field.Set(s)
+	} else {
+		args = append(args, rest...)
+	}
+
 	argIdx := 0
 	for _, meta := range node.Args {
 		if meta.IsGreedy {
 			if len(args) > argIdx {
-				for _, v := range args[argIdx:] {
-					if err := resolver.BindValue(meta.Field, v); err != nil {
-						return err
-					}
+				if err := resolver.BindSlice(meta.Field, args[argIdx:]); err != nil {
+					return fmt.Errorf("invalid value for argument %s: %w", meta.Name, err)
 				}
 			} else if meta.Required {
 				return fmt.Errorf("missing required positional arguments: %s", meta.Description)
@@ -431,10 +1048,8 @@ func bindArgs(node *parser.CommandNode, args []string) error {
 	return nil
 }
 
-// injectDependencies injects the logger and context into the command struct if it embeds the Base struct.
-func injectDependencies(node *parser.CommandNode) {
-	logger := log.New()
-
+// injectDependencies injects the logger, context, streams and resolved flags into the command struct if it embeds the Base struct.
+func injectDependencies(node *parser.CommandNode, base Base) {
 	val := node.Value
 	if val.Kind() == reflect.Ptr {
 		val = val.Elem()
@@ -446,10 +1061,6 @@ func injectDependencies(node *parser.CommandNode) {
 
 		if fieldType.Type == reflect.TypeFor[Base]() {
 			if field.CanSet() {
-				base := Base{
-					Logger: logger,
-					Ctx:    context.Background(),
-				}
 				field.Set(reflect.ValueOf(base))
 			}
 		}
@@ -0,0 +1,17 @@
+package cli
+
+import (
+	"io"
+	"testing"
+)
+
+// newTestApp returns an App for root whose output is discarded.
+func newTestApp(t *testing.T, root any, opts ...Option) *App {
+	t.Helper()
+	app, err := New(root, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Stdout, app.Stderr = io.Discard, io.Discard
+	return app
+}
@@ -2,12 +2,36 @@ package cli
 
 import (
 	"context"
+	"io"
 
 	"github.com/mirkobrombin/go-cli-builder/v2/pkg/log"
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/parser"
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
 )
 
 // Base is a struct that can be embedded in commands to provide common functionality.
 type Base struct {
 	Logger log.Logger      `internal:"ignore"`
 	Ctx    context.Context `internal:"ignore"`
+	Stdin  io.Reader       `internal:"ignore"`
+	Stdout io.Writer       `internal:"ignore"`
+	Stderr io.Writer       `internal:"ignore"`
+
+	flags map[string]*parser.FlagMetadata
+}
+
+// FlagOrigin returns where the value of the named flag came from. Flags of the
+// command and of all its parents are visible.
+//
+// Example:
+//
+//	if origin, ok := c.FlagOrigin("token"); ok {
+//		c.Logger.Info("token read from %s", origin)
+//	}
+func (b *Base) FlagOrigin(name string) (resolver.Origin, bool) {
+	meta, ok := b.flags[name]
+	if !ok {
+		return resolver.Origin{}, false
+	}
+	return meta.Origin, true
 }
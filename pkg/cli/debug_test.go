@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type debugCmd struct {
+	Token string  `cli:"token" secret:"true"`
+	Name  *string `cli:"name"`
+	Port  int     `cli:"port"`
+	Host  string  `cli:"host" default:"localhost"`
+}
+
+func (c *debugCmd) Run() error { return nil }
+
+func TestDebugFlagsReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("# settings\nport: 8080\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	app := newTestApp(t, &debugCmd{}, WithConfigPaths(path))
+	var out bytes.Buffer
+	app.Stdout = &out
+
+	if err := app.RunWithArgs(context.Background(), []string{"--debug-flags", "--token", "s3cr3t"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "" +
+		"FLAG     VALUE      SOURCE\n" +
+		"--host   localhost  default\n" +
+		"--name   <unset>    none\n" +
+		"--port   8080       config " + path + ":2 (port)\n" +
+		"--token  ********   flag --token\n"
+	if out.String() != want {
+		t.Errorf("report:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
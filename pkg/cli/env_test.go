@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
+)
+
+type envDeployCmd struct {
+	Region string `cli:"region"`
+	Zone   string `flag:"long:zone" env:"-"`
+	Pool   string `flag:"long:pool" env:"ENV_TEST_POOL"`
+	Size   string `flag:"long:size"`
+}
+
+func (c *envDeployCmd) Run() error { return nil }
+
+type envRoot struct {
+	Deploy *envDeployCmd `cmd:"deploy"`
+}
+
+func TestEnvPrefix(t *testing.T) {
+	t.Setenv("MYT_DEPLOY_REGION", "eu")
+	t.Setenv("MYT_DEPLOY_ZONE", "a")
+	t.Setenv("MYT_DEPLOY_POOL", "derived")
+	t.Setenv("ENV_TEST_POOL", "explicit")
+	t.Setenv("MYT_DEPLOY_SIZE", "large")
+
+	root := &envRoot{Deploy: &envDeployCmd{}}
+	app := newTestApp(t, root, WithEnvPrefix("MYT"))
+
+	if err := app.RunWithArgs(context.Background(), []string{"deploy"}); err != nil {
+		t.Fatal(err)
+	}
+	want := envDeployCmd{Region: "eu", Pool: "explicit", Size: "large"}
+	if *root.Deploy != want {
+		t.Errorf("bound %+v, want %+v", *root.Deploy, want)
+	}
+}
+
+type envTokenCmd struct {
+	Token string `cli:"token" env:"API_TOKEN"`
+	Host  string `cli:"host" env:"ENV_TEST_HOST"`
+}
+
+func (c *envTokenCmd) Run() error { return nil }
+
+func TestWithEnv(t *testing.T) {
+	t.Setenv("API_TOKEN", "real")
+	t.Setenv("ENV_TEST_HOST", "real.example.com")
+
+	cmd := &envTokenCmd{}
+	app := newTestApp(t, cmd, WithEnv(resolver.MapSource{"API_TOKEN": "test"}))
+
+	if err := app.RunWithArgs(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	want := envTokenCmd{Token: "test"}
+	if *cmd != want {
+		t.Errorf("bound %+v, want %+v", *cmd, want)
+	}
+	if origin, _ := app.FlagOrigin("token"); origin.Source != resolver.SourceEnv || origin.Name != "API_TOKEN" {
+		t.Errorf("FlagOrigin(token) = %v, want env API_TOKEN", origin)
+	}
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	// ExitFailure is the exit code of commands failing with an error which
+	// does not implement ExitCoder.
+	ExitFailure = 1
+	// ExitUsage is the exit code of usage errors, such as unknown flags or
+	// invalid flag values.
+	ExitUsage = 2
+)
+
+// ExitCoder is implemented by errors carrying the exit code of the process.
+type ExitCoder interface {
+	error
+	ExitCode() int
+}
+
+// exitError is an error with an exit code, returned by Exit.
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e *exitError) Error() string {
+	return e.msg
+}
+
+func (e *exitError) ExitCode() int {
+	return e.code
+}
+
+// Exit returns an error making Main exit with the given code, after printing
+// msg unless it is empty.
+//
+// Example:
+//
+//	if !found {
+//		return cli.Exit(3, "item not found")
+//	}
+func Exit(code int, msg string) error {
+	return &exitError{code: code, msg: msg}
+}
+
+// UsageError wraps errors caused by the invocation of the application, such
+// as unknown flags, missing required flags or invalid values. It has already
+// been reported, along with the usage, when returned by Run.
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns ExitUsage, or the code of the wrapped error if it implements ExitCoder.
+func (e *UsageError) ExitCode() int {
+	var coder ExitCoder
+	if errors.As(e.Err, &coder) {
+		return coder.ExitCode()
+	}
+	return ExitUsage
+}
+
+// ExitCode returns the exit code for an error returned by Run: 0 for nil, the
+// code of the first ExitCoder in the chain, or ExitFailure.
+//
+// Example:
+//
+//	err := app.RunWithArgs(ctx, []string{"--unknown"})
+//	fmt.Println(cli.ExitCode(err)) // 2
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var coder ExitCoder
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+	return ExitFailure
+}
+
+// Main runs the application with the arguments of the process and exits with
+// the code of the returned error, printing it to Stderr first unless it is a
+// UsageError, which Run already reported. It returns normally on success.
+//
+// Example:
+//
+//	func main() {
+//		app, err := cli.New(&CLI{})
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		app.Main()
+//	}
+func (a *App) Main() {
+	err := a.Run()
+	if err == nil {
+		return
+	}
+
+	var usage *UsageError
+	if !errors.As(err, &usage) && err.Error() != "" {
+		_, _, stderr := a.streams()
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+	}
+	os.Exit(ExitCode(err))
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type exitCmd struct {
+	err error
+}
+
+func (c *exitCmd) Run() error { return c.err }
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  error
+		want int
+	}{
+		{name: "success", want: 0},
+		{name: "plain error", err: errors.New("failed"), want: ExitFailure},
+		{name: "exit", err: Exit(3, "not found"), want: 3},
+		{name: "wrapped exit", err: fmt.Errorf("lookup: %w", Exit(3, "not found")), want: 3},
+		{name: "unknown flag", args: []string{"--unknown"}, want: ExitUsage},
+		{name: "usage error with exit code", err: &UsageError{Err: Exit(4, "bad input")}, want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, &exitCmd{err: tt.err})
+
+			err := app.RunWithArgs(context.Background(), tt.args)
+			if got := ExitCode(err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsageErrorExitCode(t *testing.T) {
+	if got := (&UsageError{Err: errors.New("bad flag")}).ExitCode(); got != ExitUsage {
+		t.Errorf("ExitCode() = %d, want %d", got, ExitUsage)
+	}
+	err := &UsageError{Err: fmt.Errorf("parse: %w", Exit(5, "bad input"))}
+	if got := err.ExitCode(); got != 5 {
+		t.Errorf("ExitCode() = %d, want 5", got)
+	}
+	if err.Error() != "parse: bad input" {
+		t.Errorf("Error() = %q, want %q", err.Error(), "parse: bad input")
+	}
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/parser"
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
+)
+
+// flagRefPrefix introduces references to other flags, e.g. ${flag:data-dir}.
+const flagRefPrefix = "flag:"
+
+// resolvedFlag holds the raw values of a flag and their origin.
+type resolvedFlag struct {
+	values []string
+	origin resolver.Origin
+}
+
+// expandError is an expansion error of a flag.
+type expandError struct {
+	flag string
+	err  error
+}
+
+func (e *expandError) Error() string {
+	return fmt.Sprintf("flag --%s: %v", e.flag, e.err)
+}
+
+func (e *expandError) Unwrap() error {
+	return e.err
+}
+
+// expander interpolates variables and flag references in resolved values.
+type expander struct {
+	flags    map[string]*parser.FlagMetadata
+	resolved map[string]*resolvedFlag
+	sources  map[resolver.SourceKind]bool
+	env      resolver.EnvLookup
+	state    map[string]int // 1 while expanding, 2 once done
+	stack    []string
+}
+
+// expandValues expands the values of every flag coming from one of the given
+// sources. Variables are read from env, or
+// from the process environment when env is nil.
+func expandValues(resolved map[string]*resolvedFlag, flags map[string]*parser.FlagMetadata, sources map[resolver.SourceKind]bool, env resolver.EnvLookup) error {
+	e := &expander{
+		flags:    flags,
+		resolved: resolved,
+		sources:  sources,
+		env:      env,
+		state:    make(map[string]int, len(flags)),
+	}
+	// Flags are visited in order so that errors, such as cycles, are stable.
+	for _, name := range slices.Sorted(maps.Keys(resolved)) {
+		if err := e.expand(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expand expands the values of the named flag, after the flags it references.
+func (e *expander) expand(name string) error {
+	switch e.state[name] {
+	case 1:
+		cycle := append(e.stack[slices.Index(e.stack, name):], name)
+		return &expandError{flag: name, err: fmt.Errorf("reference cycle: --%s", strings.Join(cycle, " -> --"))}
+	case 2:
+		return nil
+	}
+
+	r := e.resolved[name]
+	source := r.origin.Source
+	if !e.sources[source] {
+		e.state[name] = 2
+		return nil
+	}
+
+	e.state[name] = 1
+	e.stack = append(e.stack, name)
+	// The values may be shared with the provider, so they are expanded in a copy.
+	values := slices.Clone(r.values)
+	for i, v := range values {
+		expanded, err := resolver.ExpandHome(v, e.lookup)
+		if err == nil {
+			expanded, err = resolver.Expand(expanded, e.lookup)
+		}
+		if err != nil {
+			if errors.As(err, new(*expandError)) {
+				return err
+			}
+			if e.flags[name].Secret {
+				v = resolver.SecretMask
+			}
+			return &expandError{flag: name, err: fmt.Errorf("cannot expand %s value %q: %w", source, v, err)}
+		}
+		values[i] = expanded
+	}
+	r.values = values
+	e.stack = e.stack[:len(e.stack)-1]
+	e.state[name] = 2
+	return nil
+}
+
+// lookup resolves an environment variable or a ${flag:name} reference.
+func (e *expander) lookup(name string) (string, bool, error) {
+	ref, isFlag := strings.CutPrefix(name, flagRefPrefix)
+	if !isFlag {
+		if e.env == nil {
+			val, ok := os.LookupEnv(name)
+			return val, ok, nil
+		}
+		val, _, ok := e.env.LookupEnv(name)
+		return val, ok, nil
+	}
+
+	if _, ok := e.flags[ref]; !ok {
+		return "", false, fmt.Errorf("unknown flag --%s", ref)
+	}
+	if err := e.expand(ref); err != nil {
+		return "", false, err
+	}
+	r := e.resolved[ref]
+	if len(r.values) == 0 {
+		return "", false, nil
+	}
+	return strings.Join(r.values, ","), true, nil
+}
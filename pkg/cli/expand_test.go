@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
+)
+
+type expandCmd struct {
+	Cache string `cli:"cache" default:"${EXPAND_TEST_XDG:-${EXPAND_TEST_HOME}/.cache}/tool"`
+	Logs  string `cli:"logs" default:"${flag:cache}/logs"`
+}
+
+func (c *expandCmd) Run() error { return nil }
+
+type cycleCmd struct {
+	A string `cli:"a" default:"${flag:b}"`
+	B string `cli:"b" default:"x${flag:c}"`
+	C string `cli:"c" default:"${flag:a}"`
+}
+
+func (c *cycleCmd) Run() error { return nil }
+
+func TestExpandNestedFallback(t *testing.T) {
+	t.Setenv("EXPAND_TEST_HOME", "/home/me")
+
+	cmd := &expandCmd{}
+	app := newTestApp(t, cmd, WithExpansion())
+
+	if err := app.RunWithArgs(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Cache != "/home/me/.cache/tool" || cmd.Logs != "/home/me/.cache/tool/logs" {
+		t.Errorf("cache = %q, logs = %q", cmd.Cache, cmd.Logs)
+	}
+}
+
+func TestExpandCycle(t *testing.T) {
+	app := newTestApp(t, &cycleCmd{}, WithExpansion())
+
+	err := app.RunWithArgs(context.Background(), nil)
+	want := "flag --a: reference cycle: --a -> --b -> --c -> --a"
+	if err == nil || err.Error() != want {
+		t.Errorf("RunWithArgs() error = %v, want %q", err, want)
+	}
+}
+
+type expandSourceCmd struct {
+	Paths []string `cli:"paths"`
+}
+
+func (c *expandSourceCmd) Run() error { return nil }
+
+func TestExpandKeepsSourceValues(t *testing.T) {
+	t.Setenv("EXPAND_TEST_HOME", "/home/me")
+
+	shared := []string{"$EXPAND_TEST_HOME/a", "b"}
+	source := resolver.ProviderFunc(func(q resolver.Query) ([]string, resolver.Origin, bool, error) {
+		return shared, resolver.Origin{Source: resolver.SourceExternal, Name: "test"}, true, nil
+	})
+
+	cmd := &expandSourceCmd{}
+	app := newTestApp(t, cmd, WithSource(source), WithExpansion(resolver.SourceExternal))
+
+	if err := app.RunWithArgs(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/home/me/a", "b"}; !slices.Equal(cmd.Paths, want) {
+		t.Errorf("paths = %q, want %q", cmd.Paths, want)
+	}
+	if want := []string{"$EXPAND_TEST_HOME/a", "b"}; !slices.Equal(shared, want) {
+		t.Errorf("source values = %q, want %q", shared, want)
+	}
+}
+
+type homeCmd struct {
+	Tilde string `cli:"tilde" default:"~/x"`
+	Var   string `cli:"var" default:"$HOME/x"`
+}
+
+func (c *homeCmd) Run() error { return nil }
+
+func TestExpandHomeWithEnv(t *testing.T) {
+	cmd := &homeCmd{}
+	app := newTestApp(t, cmd, WithEnv(resolver.MapSource{"HOME": "/home/test"}), WithExpansion())
+
+	if err := app.RunWithArgs(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Tilde != "/home/test/x" || cmd.Var != "/home/test/x" {
+		t.Errorf("tilde = %q, var = %q, want /home/test/x", cmd.Tilde, cmd.Var)
+	}
+}
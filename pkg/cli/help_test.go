@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// level is a Value accepting low or high.
+type level string
+
+func (l *level) Set(s string) error {
+	if s != "low" && s != "high" {
+		return fmt.Errorf("expected low or high, got %q", s)
+	}
+	*l = level(s)
+	return nil
+}
+
+func (l *level) String() string { return string(*l) }
+func (l *level) Type() string   { return "level" }
+
+type helpCmd struct {
+	Level level `cli:"level"`
+}
+
+func (c *helpCmd) Run() error { return nil }
+
+func TestHelpShowsInitialValueDefault(t *testing.T) {
+	cmd := &helpCmd{Level: "low"}
+	app := newTestApp(t, cmd)
+	var out bytes.Buffer
+	app.Stdout = &out
+
+	if err := app.RunWithArgs(context.Background(), []string{"--level", "high"}); err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Level != "high" {
+		t.Fatalf("Level = %q, want high", cmd.Level)
+	}
+
+	out.Reset()
+	if err := app.RunWithArgs(context.Background(), []string{"--help"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "(default: low)") {
+		t.Errorf("help does not show the initial default:\n%s", out.String())
+	}
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/parser"
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
+)
+
+// defaultMaxValueSize is the default limit of flag values read from files and stdin.
+const defaultMaxValueSize = 1 << 20
+
+// stdinOrigin is the file name recorded in the origin of values read from stdin.
+const stdinOrigin = "<stdin>"
+
+// inputReader reads flag values given as @file or "-" on the command line.
+type inputReader struct {
+	stdin     io.Reader
+	maxSize   int64
+	stdinUsed bool
+}
+
+// readValues replaces @file values of flags reading from files, and "-" values
+// of flags reading from stdin, with their content. Secret flags always accept
+// "-", and drop the trailing newline of the content. The origin records the
+// file read.
+func (r *inputReader) readValues(meta *parser.FlagMetadata, values []string, origin *resolver.Origin) ([]string, error) {
+	out := make([]string, len(values))
+	for i, v := range values {
+		if !readsInput(meta, v) {
+			out[i] = v
+			continue
+		}
+		var content string
+		var err error
+		if v == "-" {
+			content, err = r.readStdin()
+			origin.File = stdinOrigin
+		} else {
+			content, err = r.readFile(v[1:])
+			origin.File = v[1:]
+		}
+		if err != nil {
+			return nil, err
+		}
+		if meta.Secret {
+			content = trimNewline(content)
+		}
+		out[i] = content
+	}
+	return out, nil
+}
+
+// readsInput reports whether readValues replaces v with the content of stdin
+// or of a file.
+func readsInput(meta *parser.FlagMetadata, v string) bool {
+	return v == "-" && (meta.FromStdin || meta.Secret) || strings.HasPrefix(v, "@") && meta.FromFile
+}
+
+// readStdin reads the whole stdin, which can only be consumed once.
+func (r *inputReader) readStdin() (string, error) {
+	if r.stdinUsed {
+		return "", fmt.Errorf("stdin can only be read by one value")
+	}
+	r.stdinUsed = true
+	return readLimited(r.stdin, "stdin", r.maxSize)
+}
+
+// readFile reads the content of a file.
+func (r *inputReader) readFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return readLimited(f, path, r.maxSize)
+}
+
+// readLimited reads r, failing when it holds more than limit bytes.
+func readLimited(r io.Reader, name string, limit int64) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return "", fmt.Errorf("cannot read %s: %w", name, err)
+	}
+	if int64(len(data)) > limit {
+		return "", fmt.Errorf("%s exceeds the maximum size of %d bytes", name, limit)
+	}
+	return string(data), nil
+}
+
+// trimNewline removes the trailing line break of a value read from a file.
+func trimNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type inputCmd struct {
+	File  string   `cli:"file" from:"file"`
+	In    string   `cli:"in" from:"stdin"`
+	Both  []string `cli:"both" from:"file,stdin"`
+	Plain string   `cli:"plain"`
+}
+
+func (c *inputCmd) Run() error { return nil }
+
+func TestInputValues(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "value.txt")
+	if err := os.WriteFile(path, []byte("from file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.txt")
+
+	tests := []struct {
+		name    string
+		args    []string
+		stdin   string
+		maxSize int64
+		want    inputCmd
+		err     string
+	}{
+		{
+			name: "file",
+			args: []string{"--file", "@" + path},
+			want: inputCmd{File: "from file\n"},
+		},
+		{
+			name:  "stdin",
+			args:  []string{"--in", "-"},
+			stdin: "from stdin",
+			want:  inputCmd{In: "from stdin"},
+		},
+		{
+			name:  "file and stdin in a list",
+			args:  []string{"--both", "@" + path, "--both", "-", "--both", "x"},
+			stdin: "from stdin",
+			want:  inputCmd{Both: []string{"from file\n", "from stdin", "x"}},
+		},
+		{
+			name: "literal values",
+			args: []string{"--plain", "@" + path, "--file", "-", "--in", "@x"},
+			want: inputCmd{Plain: "@" + path, File: "-", In: "@x"},
+		},
+		{
+			name:  "stdin read twice",
+			args:  []string{"--both", "-", "--both", "-"},
+			stdin: "once",
+			err:   "flag --both: stdin can only be read by one value",
+		},
+		{
+			name: "missing file",
+			args: []string{"--file", "@" + missing},
+			err:  "flag --file: open " + missing + ": no such file or directory",
+		},
+		{
+			name:    "file too large",
+			args:    []string{"--file", "@" + path},
+			maxSize: 4,
+			err:     "flag --file: " + path + " exceeds the maximum size of 4 bytes",
+		},
+		{
+			name:    "stdin too large",
+			args:    []string{"--in", "-"},
+			stdin:   "12345",
+			maxSize: 4,
+			err:     "flag --in: stdin exceeds the maximum size of 4 bytes",
+		},
+		{
+			name:    "within the limit",
+			args:    []string{"--in", "-"},
+			stdin:   "1234",
+			maxSize: 4,
+			want:    inputCmd{In: "1234"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &inputCmd{}
+			var opts []Option
+			if tt.maxSize > 0 {
+				opts = append(opts, WithMaxValueSize(tt.maxSize))
+			}
+			app := newTestApp(t, cmd, opts...)
+			app.Stdin = strings.NewReader(tt.stdin)
+
+			err := app.RunWithArgs(context.Background(), tt.args)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("RunWithArgs() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*cmd, tt.want) {
+				t.Errorf("bound %+v, want %+v", *cmd, tt.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,11 @@
 package cli
 
+import (
+	"context"
+
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
+)
+
 // Runner is an interface for commands that can be run.
 type Runner interface {
 	Run() error
@@ -14,3 +20,38 @@ type BeforeRunner interface {
 type AfterRunner interface {
 	After() error
 }
+
+// ContextRunner is an interface for commands that can be run with the context
+// of the invocation. It takes precedence over Runner when both are implemented.
+type ContextRunner interface {
+	RunContext(ctx context.Context) error
+}
+
+// BeforeContextRunner is the context-aware variant of BeforeRunner, taking
+// precedence over it when both are implemented.
+type BeforeContextRunner interface {
+	BeforeContext(ctx context.Context) error
+}
+
+// AfterContextRunner is the context-aware variant of AfterRunner, taking
+// precedence over it when both are implemented.
+type AfterContextRunner interface {
+	AfterContext(ctx context.Context) error
+}
+
+// ErrorHandler is an interface for commands handling the errors of their
+// hooks and of the commands below them. OnError may return err, wrap it or
+// return nil to swallow it.
+type ErrorHandler interface {
+	OnError(err error) error
+}
+
+// Value is an interface for custom flag and argument types.
+// Set is called for every occurrence of the flag, so implementations may
+// accumulate values.
+type Value = resolver.Value
+
+// Source supplies flag values not given on the command line, such as secret
+// stores or keyrings. Lookup receives the flag name, its environment variable
+// and configuration key, and reports whether a value was found and its origin.
+type Source = resolver.Provider
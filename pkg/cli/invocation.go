@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/parser"
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
+)
+
+// Invocation describes a run of the application. It is passed to the
+// middleware and attached to the context of the lifecycle methods, including
+// Base.Ctx, where it is read with InvocationFrom.
+type Invocation struct {
+	Args       []string                   // Arguments of the run, after response file expansion, with secret values masked
+	Path       []string                   // Command names from the root to the executed command
+	Nodes      []*parser.CommandNode      // Command nodes from the root to the executed command
+	Command    any                        // Bound struct of the executed command, as a pointer when addressable
+	Positional []string                   // Positional arguments of the executed command
+	Rest       []string                   // Arguments following the "--" terminator
+	Sources    map[string]resolver.Origin // Where the value of each flag came from
+}
+
+// invocationKey is the context key of the Invocation.
+type invocationKey struct{}
+
+// withInvocation returns a copy of ctx carrying inv.
+func withInvocation(ctx context.Context, inv *Invocation) context.Context {
+	return context.WithValue(ctx, invocationKey{}, inv)
+}
+
+// InvocationFrom returns the Invocation carried by the context of a run.
+//
+// Example:
+//
+//	func (c *DeployCmd) RunContext(ctx context.Context) error {
+//		inv, _ := cli.InvocationFrom(ctx)
+//		fmt.Println("running", strings.Join(inv.Path, " "))
+//		return nil
+//	}
+func InvocationFrom(ctx context.Context) (*Invocation, bool) {
+	inv, ok := ctx.Value(invocationKey{}).(*Invocation)
+	return inv, ok
+}
+
+// commandValue returns the bound struct of a node, as a pointer when addressable.
+func commandValue(node *parser.CommandNode) any {
+	if node.Value.Kind() != reflect.Ptr && node.Value.CanAddr() {
+		return node.Value.Addr().Interface()
+	}
+	return node.Value.Interface()
+}
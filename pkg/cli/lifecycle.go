@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/parser"
+)
+
+// hook returns the command of a node as T, looking at the pointer receiver
+// too when the value is addressable.
+func hook[T any](node *parser.CommandNode) (T, bool) {
+	if h, ok := node.Value.Interface().(T); ok {
+		return h, true
+	}
+	if node.Value.CanAddr() {
+		if h, ok := node.Value.Addr().Interface().(T); ok {
+			return h, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// runBefore calls the Before hook of a node, preferring BeforeContext.
+func runBefore(ctx context.Context, node *parser.CommandNode) error {
+	if h, ok := hook[BeforeContextRunner](node); ok {
+		return h.BeforeContext(ctx)
+	}
+	if h, ok := hook[BeforeRunner](node); ok {
+		return h.Before()
+	}
+	return nil
+}
+
+// runCommand calls the Run method of a node, preferring RunContext, and
+// reports whether the node is runnable.
+func runCommand(ctx context.Context, node *parser.CommandNode) (bool, error) {
+	if h, ok := hook[ContextRunner](node); ok {
+		return true, h.RunContext(ctx)
+	}
+	if h, ok := hook[Runner](node); ok {
+		return true, h.Run()
+	}
+	return false, nil
+}
+
+// runAfter calls the After hook of a node, preferring AfterContext.
+func runAfter(ctx context.Context, node *parser.CommandNode) error {
+	if h, ok := hook[AfterContextRunner](node); ok {
+		return h.AfterContext(ctx)
+	}
+	if h, ok := hook[AfterRunner](node); ok {
+		return h.After()
+	}
+	return nil
+}
+
+// runOnError calls the OnError hook of a node, which may wrap or swallow err.
+func runOnError(node *parser.CommandNode, err error) error {
+	if h, ok := hook[ErrorHandler](node); ok {
+		return h.OnError(err)
+	}
+	return err
+}
+
+// runLifecycle runs the hooks of the nodes in path around run, which executes
+// the command. Each node behaves like a try/catch/finally block enclosing the
+// nodes after it:
+//
+//   - Before hooks run in path order, stopping at the first failure.
+//   - The After hook of every node whose Before succeeded, or which has none,
+//     is deferred, so After hooks run in reverse order even when a later hook
+//     or the command fails or panics.
+//   - An error is passed to the OnError hooks from the node where it happened
+//     up to the root, before the After hook of each node; a hook returning
+//     nil swallows it.
+//
+// Errors returned by After hooks are joined with the pending error.
+func runLifecycle(ctx context.Context, path []*parser.CommandNode, run func() error) error {
+	if len(path) == 0 {
+		return run()
+	}
+
+	node := path[0]
+	if err := runBefore(ctx, node); err != nil {
+		return runOnError(node, err)
+	}
+
+	return func() (err error) {
+		defer func() {
+			if afterErr := runAfter(ctx, node); afterErr != nil {
+				err = joinErrors(err, afterErr)
+			}
+		}()
+
+		if err := runLifecycle(ctx, path[1:], run); err != nil {
+			return runOnError(node, err)
+		}
+		return nil
+	}()
+}
+
+// joinErrors joins two errors, returning the other one when either is nil.
+func joinErrors(err, other error) error {
+	if err == nil {
+		return other
+	}
+	return errors.Join(err, other)
+}
@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/parser"
+)
+
+// lifecycleHooks records the calls to its hooks.
+type lifecycleHooks struct {
+	id      int
+	calls   *[]string
+	before  error
+	after   error
+	onError func(error) error
+}
+
+func (h *lifecycleHooks) Before() error {
+	*h.calls = append(*h.calls, fmt.Sprintf("before %d", h.id))
+	return h.before
+}
+
+func (h *lifecycleHooks) After() error {
+	*h.calls = append(*h.calls, fmt.Sprintf("after %d", h.id))
+	return h.after
+}
+
+func (h *lifecycleHooks) OnError(err error) error {
+	*h.calls = append(*h.calls, fmt.Sprintf("onerror %d: %v", h.id, err))
+	if h.onError != nil {
+		return h.onError(err)
+	}
+	return err
+}
+
+func TestRunLifecycle(t *testing.T) {
+	errRun := errors.New("run failed")
+	errBefore := errors.New("before failed")
+	errAfter1 := errors.New("after 1 failed")
+	errAfter2 := errors.New("after 2 failed")
+	swallow := func(error) error { return nil }
+
+	tests := []struct {
+		name     string
+		hooks    []lifecycleHooks
+		run      error
+		panics   bool
+		want     []string
+		wantErrs []error
+		err      string
+	}{
+		{
+			name:  "success",
+			hooks: []lifecycleHooks{{}, {}, {}},
+			want:  []string{"before 0", "before 1", "before 2", "run", "after 2", "after 1", "after 0"},
+		},
+		{
+			name:  "run fails",
+			hooks: []lifecycleHooks{{}, {}, {}},
+			run:   errRun,
+			want: []string{
+				"before 0", "before 1", "before 2", "run",
+				"onerror 2: run failed", "after 2",
+				"onerror 1: run failed", "after 1",
+				"onerror 0: run failed", "after 0",
+			},
+			err: "run failed",
+		},
+		{
+			name:  "before fails",
+			hooks: []lifecycleHooks{{}, {before: errBefore}, {}},
+			want:  []string{"before 0", "before 1", "onerror 1: before failed", "onerror 0: before failed", "after 0"},
+			err:   "before failed",
+		},
+		{
+			name:  "error swallowed",
+			hooks: []lifecycleHooks{{}, {onError: swallow}, {}},
+			run:   errRun,
+			want: []string{
+				"before 0", "before 1", "before 2", "run",
+				"onerror 2: run failed", "after 2",
+				"onerror 1: run failed", "after 1",
+				"after 0",
+			},
+		},
+		{
+			name:  "after errors joined",
+			hooks: []lifecycleHooks{{after: errAfter1}, {}, {after: errAfter2}},
+			run:   errRun,
+			want: []string{
+				"before 0", "before 1", "before 2", "run",
+				"onerror 2: run failed", "after 2",
+				"onerror 1: run failed\nafter 2 failed", "after 1",
+				"onerror 0: run failed\nafter 2 failed", "after 0",
+			},
+			wantErrs: []error{errRun, errAfter1, errAfter2},
+			err:      "run failed\nafter 2 failed\nafter 1 failed",
+		},
+		{
+			name:  "after error on success",
+			hooks: []lifecycleHooks{{}, {after: errAfter1}},
+			want:  []string{"before 0", "before 1", "run", "after 1", "onerror 0: after 1 failed", "after 0"},
+			err:   "after 1 failed",
+		},
+		{
+			name:   "panic",
+			hooks:  []lifecycleHooks{{}, {}, {}},
+			panics: true,
+			want:   []string{"before 0", "before 1", "before 2", "run", "after 2", "after 1", "after 0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			var path []*parser.CommandNode
+			for i := range tt.hooks {
+				h := &tt.hooks[i]
+				h.id, h.calls = i, &calls
+				path = append(path, &parser.CommandNode{Value: reflect.ValueOf(h)})
+			}
+
+			var err error
+			panicked := func() (panicked bool) {
+				defer func() { panicked = recover() != nil }()
+				err = runLifecycle(context.Background(), path, func() error {
+					calls = append(calls, "run")
+					if tt.panics {
+						panic("run panicked")
+					}
+					return tt.run
+				})
+				return false
+			}()
+
+			if panicked != tt.panics {
+				t.Errorf("panicked = %v, want %v", panicked, tt.panics)
+			}
+			if !slices.Equal(calls, tt.want) {
+				t.Errorf("calls = %q, want %q", calls, tt.want)
+			}
+			if tt.err == "" && err != nil || tt.err != "" && (err == nil || err.Error() != tt.err) {
+				t.Errorf("runLifecycle() error = %v, want %q", err, tt.err)
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("runLifecycle() error = %v, does not wrap %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+// bothHooksCmd implements the plain and the context-aware variant of every hook.
+type bothHooksCmd struct {
+	calls []string
+	path  []string
+}
+
+func (c *bothHooksCmd) Before() error {
+	c.calls = append(c.calls, "before")
+	return nil
+}
+
+func (c *bothHooksCmd) BeforeContext(ctx context.Context) error {
+	c.calls = append(c.calls, "before context")
+	return nil
+}
+
+func (c *bothHooksCmd) Run() error {
+	c.calls = append(c.calls, "run")
+	return nil
+}
+
+func (c *bothHooksCmd) RunContext(ctx context.Context) error {
+	c.calls = append(c.calls, "run context")
+	if inv, ok := InvocationFrom(ctx); ok {
+		c.path = inv.Path
+	}
+	return nil
+}
+
+func (c *bothHooksCmd) After() error {
+	c.calls = append(c.calls, "after")
+	return nil
+}
+
+func (c *bothHooksCmd) AfterContext(ctx context.Context) error {
+	c.calls = append(c.calls, "after context")
+	return nil
+}
+
+func TestContextHooksPreferred(t *testing.T) {
+	cmd := &bothHooksCmd{}
+	app := newTestApp(t, cmd)
+
+	if err := app.RunWithArgs(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"before context", "run context", "after context"}
+	if !slices.Equal(cmd.calls, want) {
+		t.Errorf("calls = %q, want %q", cmd.calls, want)
+	}
+	if len(cmd.path) != 1 {
+		t.Errorf("Invocation.Path = %q, want the root command only", cmd.path)
+	}
+}
+
+func TestInvocationFrom(t *testing.T) {
+	if inv, ok := InvocationFrom(context.Background()); ok || inv != nil {
+		t.Errorf("InvocationFrom(Background) = %v, %v, want nil, false", inv, ok)
+	}
+
+	want := &Invocation{Path: []string{"root", "child"}}
+	inv, ok := InvocationFrom(withInvocation(context.Background(), want))
+	if !ok || inv != want {
+		t.Errorf("InvocationFrom() = %v, %v, want %v, true", inv, ok, want)
+	}
+}
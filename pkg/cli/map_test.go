@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
+)
+
+type mapCmd struct {
+	Labels map[string]string `cli:"label" env:"LABELS" help:"Labels to apply"`
+	First  map[string]int    `cli:"first" duplicates:"first"`
+	Strict map[string]string `cli:"strict" duplicates:"error"`
+}
+
+func (c *mapCmd) Run() error { return nil }
+
+func TestMapFlags(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		env    resolver.MapSource
+		labels map[string]string
+		first  map[string]int
+		strict map[string]string
+		err    string
+	}{
+		{
+			name:   "last wins by default",
+			args:   []string{"--label", "a=1", "--label", "b=2", "--label", "a=3"},
+			labels: map[string]string{"a": "3", "b": "2"},
+		},
+		{
+			name:  "first wins",
+			args:  []string{"--first", "a=1", "--first", "a=2", "--first", "b=3"},
+			first: map[string]int{"a": 1, "b": 3},
+		},
+		{
+			name:   "no duplicates",
+			args:   []string{"--strict", "a=1", "--strict", "b=2"},
+			strict: map[string]string{"a": "1", "b": "2"},
+		},
+		{
+			name: "duplicate rejected",
+			args: []string{"--strict", "a=1", "--strict", "a=2"},
+			err:  "invalid value for flag --strict: duplicate key: a",
+		},
+		{
+			name:   "env list",
+			env:    resolver.MapSource{"LABELS": "a=1,b=2"},
+			labels: map[string]string{"a": "1", "b": "2"},
+		},
+		{
+			name:   "command line over env",
+			args:   []string{"--label", "c=3"},
+			env:    resolver.MapSource{"LABELS": "a=1,b=2"},
+			labels: map[string]string{"c": "3"},
+		},
+		{
+			name: "missing separator",
+			args: []string{"--label", "a"},
+			err:  "invalid value for flag --label: expected key=value: a",
+		},
+		{
+			name: "invalid element",
+			args: []string{"--first", "a=x"},
+			err:  `invalid value for flag --first: key "a": invalid integer: x`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &mapCmd{}
+			app := newTestApp(t, cmd, WithEnv(tt.env))
+
+			err := app.RunWithArgs(context.Background(), tt.args)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("RunWithArgs() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(cmd.Labels, tt.labels) || !maps.Equal(cmd.First, tt.first) || !maps.Equal(cmd.Strict, tt.strict) {
+				t.Errorf("labels = %v, first = %v, strict = %v, want %v, %v, %v",
+					cmd.Labels, cmd.First, cmd.Strict, tt.labels, tt.first, tt.strict)
+			}
+		})
+	}
+}
+
+func TestMapFlagPlaceholder(t *testing.T) {
+	app := newTestApp(t, &mapCmd{})
+	var out bytes.Buffer
+	app.Stdout = &out
+
+	if err := app.RunWithArgs(context.Background(), []string{"--help"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "--label key=value") {
+		t.Errorf("help does not show the key=value placeholder:\n%s", out.String())
+	}
+}
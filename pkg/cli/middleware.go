@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"context"
+	"slices"
+
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/parser"
+)
+
+// Handler executes the hooks and the command of an invocation.
+type Handler func(ctx context.Context, inv *Invocation) error
+
+// Middleware wraps a Handler to add behavior around command execution, such
+// as timing, auditing or authorization. It may change the context passed to
+// next, skip next to short-circuit the invocation, or change its error.
+type Middleware func(next Handler) Handler
+
+// MiddlewareProvider is an interface for commands declaring middleware of
+// their own, applied when they are part of the command path.
+type MiddlewareProvider interface {
+	Middleware() []Middleware
+}
+
+// Use adds middleware wrapping the Before, Run and After sequence of every
+// invocation. Middleware runs in the order it was added, the first one being
+// the outermost.
+//
+// Example:
+//
+//	app.Use(func(next cli.Handler) cli.Handler {
+//		return func(ctx context.Context, inv *cli.Invocation) error {
+//			start := time.Now()
+//			err := next(ctx, inv)
+//			fmt.Fprintf(os.Stderr, "%s took %s\n", strings.Join(inv.Path, " "), time.Since(start))
+//			return err
+//		}
+//	})
+func (a *App) Use(mw ...Middleware) {
+	a.middleware = append(a.middleware, mw...)
+}
+
+// chain wraps h with the middleware of the application followed by the one
+// declared by the commands of path, from the root down.
+func (a *App) chain(h Handler, path []*parser.CommandNode) Handler {
+	mw := slices.Clone(a.middleware)
+	for _, node := range path {
+		if p, ok := hook[MiddlewareProvider](node); ok {
+			mw = append(mw, p.Middleware()...)
+		}
+	}
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = mw[i](h)
+	}
+	return h
+}
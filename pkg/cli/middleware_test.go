@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// ctxKey is the type of the context values set by the test middleware.
+type ctxKey struct{}
+
+type mwChildCmd struct {
+	Base
+
+	calls *[]string
+	err   error
+	ctx   context.Context
+}
+
+func (c *mwChildCmd) Before() error {
+	*c.calls = append(*c.calls, "before")
+	return nil
+}
+
+func (c *mwChildCmd) Run() error {
+	*c.calls = append(*c.calls, "run")
+	c.ctx = c.Ctx
+	return c.err
+}
+
+func (c *mwChildCmd) Middleware() []Middleware {
+	return []Middleware{recordMiddleware(c.calls, "child")}
+}
+
+type mwRoot struct {
+	Child *mwChildCmd `cmd:"child"`
+
+	calls *[]string
+}
+
+func (r *mwRoot) Middleware() []Middleware {
+	return []Middleware{recordMiddleware(r.calls, "root")}
+}
+
+// recordMiddleware records when it is entered and left.
+func recordMiddleware(calls *[]string, name string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, inv *Invocation) error {
+			*calls = append(*calls, name+" in")
+			err := next(ctx, inv)
+			*calls = append(*calls, name+" out")
+			return err
+		}
+	}
+}
+
+// newMiddlewareApp returns an app running the child command of a fresh root.
+func newMiddlewareApp(t *testing.T) (*App, *mwRoot, *[]string) {
+	t.Helper()
+	calls := &[]string{}
+	root := &mwRoot{Child: &mwChildCmd{calls: calls}, calls: calls}
+	return newTestApp(t, root), root, calls
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	app, _, calls := newMiddlewareApp(t)
+	app.Use(recordMiddleware(calls, "app 1"), recordMiddleware(calls, "app 2"))
+
+	if err := app.RunWithArgs(context.Background(), []string{"child"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"app 1 in", "app 2 in", "root in", "child in",
+		"before", "run",
+		"child out", "root out", "app 2 out", "app 1 out",
+	}
+	if !slices.Equal(*calls, want) {
+		t.Errorf("calls = %q, want %q", *calls, want)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	app, _, calls := newMiddlewareApp(t)
+	errDenied := errors.New("denied")
+	app.Use(func(next Handler) Handler {
+		return func(ctx context.Context, inv *Invocation) error {
+			return errDenied
+		}
+	})
+
+	err := app.RunWithArgs(context.Background(), []string{"child"})
+	if !errors.Is(err, errDenied) {
+		t.Errorf("RunWithArgs() error = %v, want %v", err, errDenied)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("calls = %q, want none", *calls)
+	}
+}
+
+func TestMiddlewareRewritesError(t *testing.T) {
+	app, root, _ := newMiddlewareApp(t)
+	errRun := errors.New("run failed")
+	root.Child.err = errRun
+	app.Use(func(next Handler) Handler {
+		return func(ctx context.Context, inv *Invocation) error {
+			if err := next(ctx, inv); err != nil {
+				return fmt.Errorf("%s: %w", inv.Path[len(inv.Path)-1], err)
+			}
+			return nil
+		}
+	})
+
+	err := app.RunWithArgs(context.Background(), []string{"child"})
+	if !errors.Is(err, errRun) || err.Error() != "child: run failed" {
+		t.Errorf("RunWithArgs() error = %v, want %q", err, "child: run failed")
+	}
+}
+
+func TestMiddlewareContext(t *testing.T) {
+	app, root, _ := newMiddlewareApp(t)
+	app.Use(func(next Handler) Handler {
+		return func(ctx context.Context, inv *Invocation) error {
+			return next(context.WithValue(ctx, ctxKey{}, "set by middleware"), inv)
+		}
+	})
+
+	if err := app.RunWithArgs(context.Background(), []string{"child"}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := root.Child.ctx
+	if got := ctx.Value(ctxKey{}); got != "set by middleware" {
+		t.Errorf("Base.Ctx value = %v, want the middleware one", got)
+	}
+	if inv, ok := InvocationFrom(ctx); !ok || !slices.Equal(inv.Path, []string{"root", "child"}) {
+		t.Errorf("InvocationFrom(Base.Ctx) = %v, %v, want path [root child]", inv, ok)
+	}
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
+)
+
+type optionalCmd struct {
+	P    *int    `cli:"p"`
+	Name *string `cli:"name"`
+}
+
+func (c *optionalCmd) Run() error { return nil }
+
+func TestOptionalResetBetweenRuns(t *testing.T) {
+	cmd := &optionalCmd{}
+	app := newTestApp(t, cmd)
+
+	if err := app.RunWithArgs(context.Background(), []string{"--p", "3"}); err != nil {
+		t.Fatal(err)
+	}
+	if cmd.P == nil || *cmd.P != 3 || !app.Changed("p") {
+		t.Fatalf("first run: P = %v, changed = %v", cmd.P, app.Changed("p"))
+	}
+
+	if err := app.RunWithArgs(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if cmd.P != nil || app.Changed("p") {
+		t.Errorf("second run: P = %v, changed = %v", cmd.P, app.Changed("p"))
+	}
+}
+
+func TestOptionalEmptyValue(t *testing.T) {
+	for _, args := range [][]string{{"--name="}, {"--name", ""}} {
+		cmd := &optionalCmd{}
+		app := newTestApp(t, cmd)
+
+		if err := app.RunWithArgs(context.Background(), args); err != nil {
+			t.Fatal(err)
+		}
+		if cmd.Name == nil || *cmd.Name != "" {
+			t.Errorf("%q: Name = %v, want pointer to empty string", args, cmd.Name)
+		}
+		if !app.Changed("name") {
+			t.Errorf("%q: name not reported as changed", args)
+		}
+	}
+}
+
+type emptyCmd struct {
+	Verbose bool     `cli:"verbose"`
+	Port    int      `cli:"port"`
+	Host    string   `cli:"host"`
+	Tags    []string `cli:"tags"`
+}
+
+func (c *emptyCmd) Run() error { return nil }
+
+func TestEmptyEnvValuesAreUnset(t *testing.T) {
+	t.Setenv("P_VERBOSE", "")
+	t.Setenv("P_PORT", "")
+	t.Setenv("P_HOST", "")
+	t.Setenv("P_TAGS", "")
+
+	cmd := &emptyCmd{Port: 80, Host: "localhost", Tags: []string{"a"}}
+	app := newTestApp(t, cmd, WithEnvPrefix("P"))
+
+	if err := app.RunWithArgs(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Verbose || cmd.Port != 80 || cmd.Host != "localhost" || len(cmd.Tags) != 1 {
+		t.Errorf("bound %+v, want the initial values", *cmd)
+	}
+	for _, name := range []string{"verbose", "port", "host", "tags"} {
+		if origin, _ := app.FlagOrigin(name); origin.Source != resolver.SourceNone {
+			t.Errorf("FlagOrigin(%s) = %v, want none", name, origin)
+		}
+	}
+}
+
+type requiredCmd struct {
+	Req string `cli:"req" required:"true"`
+}
+
+func (c *requiredCmd) Run() error { return nil }
+
+func TestRequiredRejectsEmptyValue(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		args []string
+	}{
+		{name: "flag", args: []string{"--req="}},
+		{name: "separate flag", args: []string{"--req", ""}},
+		{name: "env", env: "", args: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MYTOOL_REQ", tt.env)
+			app := newTestApp(t, &requiredCmd{}, WithEnvPrefix("MYTOOL"))
+
+			err := app.RunWithArgs(context.Background(), tt.args)
+			if err == nil || err.Error() != "missing required flag: --req" {
+				t.Errorf("RunWithArgs() error = %v, want missing required flag", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/config"
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
+	"github.com/mirkobrombin/go-foundation/pkg/options"
+)
+
+// Option is a functional option configuring an App.
+type Option = options.Option[App]
+
+// WithConfig enables configuration files for the named application. Files are
+// searched in /etc/<name>/config.*, $XDG_CONFIG_HOME/<name>/config.* and
+// ./.<name>.* (in increasing precedence), and the built-in --config flag loads
+// an explicit file on top of them.
+//
+// Example:
+//
+//	app, err := cli.New(&CLI{}, cli.WithConfig("mytool"))
+func WithConfig(name string) Option {
+	return func(a *App) {
+		a.configEnabled = true
+		a.configPaths = config.SearchPaths(name)
+	}
+}
+
+// WithConfigPaths enables configuration files, replacing the search paths.
+// Paths are listed from the lowest to the highest precedence and may omit the
+// extension to probe all supported formats.
+//
+// Example:
+//
+//	app, err := cli.New(&CLI{}, cli.WithConfigPaths("/opt/mytool/config", "mytool.toml"))
+func WithConfigPaths(paths ...string) Option {
+	return func(a *App) {
+		a.configEnabled = true
+		a.configPaths = paths
+	}
+}
+
+// WithDotEnv loads variables from dotenv files, ".env" when no path is given.
+// They are visible to env tags without modifying the process environment, whose
+// variables take precedence. Later files override earlier ones and missing
+// files are skipped.
+//
+// Example:
+//
+//	app, err := cli.New(&CLI{}, cli.WithDotEnv(".env", ".env.local"))
+func WithDotEnv(paths ...string) Option {
+	return func(a *App) {
+		if len(paths) == 0 {
+			paths = []string{".env"}
+		}
+		a.dotenvPaths = paths
+	}
+}
+
+// WithEnvPrefix derives an environment variable for every flag without an env
+// tag from the prefix, the command path and the flag name: the --region flag of
+// the deploy command becomes MYTOOL_DEPLOY_REGION. Use env:"-" to opt a flag out.
+//
+// Example:
+//
+//	app, err := cli.New(&CLI{}, cli.WithEnvPrefix("MYTOOL"))
+func WithEnvPrefix(prefix string) Option {
+	return func(a *App) {
+		a.envPrefix = prefix
+	}
+}
+
+// WithEnv replaces the process environment with env, both for env tags and
+// for the expansion of values. Dotenv files are layered under it. Tests can
+// use it to stay hermetic whatever variables are set in their environment.
+//
+// Example:
+//
+//	app, err := cli.New(&CLI{}, cli.WithEnv(resolver.MapSource{"API_TOKEN": "test"}))
+func WithEnv(env resolver.EnvLookup) Option {
+	return func(a *App) {
+		a.env = env
+	}
+}
+
+// WithSource registers value sources, queried in order after environment
+// variables and before configuration files. The value of a flag is resolved
+// with priority: CLI > Env > Sources > Config > Default.
+//
+// Example:
+//
+//	app, err := cli.New(&CLI{}, cli.WithSource(resolver.MapSource{"API_TOKEN": "test"}))
+func WithSource(sources ...Source) Option {
+	return func(a *App) {
+		a.sources = append(a.sources, sources...)
+	}
+}
+
+// WithExpansion expands default values, and values coming from the listed
+// sources, before binding them. Values may reference environment variables as
+// $VAR, ${VAR} or ${VAR:-fallback}, other flags as ${flag:name}, and start with
+// ~ for the home directory. Values given on the command line are never expanded.
+//
+// Example:
+//
+//	app, err := cli.New(&CLI{}, cli.WithExpansion(resolver.SourceEnv, resolver.SourceConfig))
+func WithExpansion(sources ...resolver.SourceKind) Option {
+	return func(a *App) {
+		a.expand = map[resolver.SourceKind]bool{resolver.SourceDefault: true}
+		for _, s := range sources {
+			a.expand[s] = true
+		}
+	}
+}
+
+// WithMaxValueSize sets the maximum size in bytes of flag values read from
+// files and stdin, 1 MiB by default.
+//
+// Example:
+//
+//	app, err := cli.New(&CLI{}, cli.WithMaxValueSize(10<<20))
+func WithMaxValueSize(size int64) Option {
+	return func(a *App) {
+		a.maxValueSize = size
+	}
+}
+
+// WithResponseFiles expands @path arguments into the arguments listed in the
+// file, split like a shell does. Response files may contain comments and
+// reference other response files; use @@ for an argument starting with @.
+//
+// Example:
+//
+//	app, err := cli.New(&CLI{}, cli.WithResponseFiles())
+func WithResponseFiles() Option {
+	return func(a *App) {
+		a.responseFiles = true
+	}
+}
+
+// WithSignalHandling enables or disables the handling of SIGINT and SIGTERM.
+// It is enabled by default for Run and Main, and disabled for RunWithArgs.
+// When enabled, the first signal cancels the context of the commands and a
+// second one exits the process.
+//
+// Example:
+//
+//	app, err := cli.New(&CLI{}, cli.WithSignalHandling(false))
+func WithSignalHandling(enabled bool) Option {
+	return func(a *App) {
+		a.signals = enabled
+		a.signalsSet = true
+	}
+}
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+type parseCmd struct {
+	Extract bool     `cli:"extract,x"`
+	Gzip    bool     `cli:"gzip,z"`
+	Verbose int      `cli:"verbose,v" type:"count"`
+	File    string   `cli:"file,f"`
+	Output  string   `cli:"output,o"`
+	Tags    []string `cli:"tag,t"`
+	Color   bool     `cli:"color"`
+}
+
+func (c *parseCmd) Run() error { return nil }
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		flags map[string][]string
+		pos   []string
+		rest  []string
+		err   string
+	}{
+		{
+			name:  "long flags",
+			args:  []string{"--file", "a.tar", "--output=out", "--color", "--no-color"},
+			flags: map[string][]string{"file": {"a.tar"}, "output": {"out"}, "color": {"true", "false"}},
+		},
+		{
+			name:  "single shorthands",
+			args:  []string{"-x", "-f", "a.tar", "-o=out"},
+			flags: map[string][]string{"extract": {"true"}, "file": {"a.tar"}, "output": {"out"}},
+		},
+		{
+			name:  "cluster",
+			args:  []string{"-xzv"},
+			flags: map[string][]string{"extract": {"true"}, "gzip": {"true"}, "verbose": {"1"}},
+		},
+		{
+			name:  "counter cluster",
+			args:  []string{"-vvv", "--verbose"},
+			flags: map[string][]string{"verbose": {"4"}},
+		},
+		{
+			name:  "trailing value in cluster",
+			args:  []string{"-xzf", "archive.tar", "src"},
+			flags: map[string][]string{"extract": {"true"}, "gzip": {"true"}, "file": {"archive.tar"}},
+			pos:   []string{"src"},
+		},
+		{
+			name:  "attached values",
+			args:  []string{"-ofile", "-xzfarchive.tar", "-tkey=val"},
+			flags: map[string][]string{"output": {"file"}, "extract": {"true"}, "gzip": {"true"}, "file": {"archive.tar"}, "tag": {"key=val"}},
+		},
+		{
+			name:  "attached value in cluster",
+			args:  []string{"-xf=archive.tar"},
+			flags: map[string][]string{"extract": {"true"}, "file": {"archive.tar"}},
+		},
+		{
+			name:  "repeated values",
+			args:  []string{"-t", "a", "--tag", "b", "-tc"},
+			flags: map[string][]string{"tag": {"a", "b", "c"}},
+		},
+		{
+			name:  "terminator",
+			args:  []string{"pos", "-x", "--", "-z", "--file"},
+			flags: map[string][]string{"extract": {"true"}},
+			pos:   []string{"pos"},
+			rest:  []string{"-z", "--file"},
+		},
+		{
+			name:  "stdin value",
+			args:  []string{"-", "-f", "-"},
+			flags: map[string][]string{"file": {"-"}},
+			pos:   []string{"-"},
+		},
+		{
+			name: "value mid-cluster",
+			args: []string{"-xfz"},
+			err:  "shorthand flag -f in -xfz needs an argument and must be the last of the group",
+		},
+		{
+			name: "missing value in cluster",
+			args: []string{"-xzf"},
+			err:  "flag needs an argument: -f",
+		},
+		{
+			name: "missing value",
+			args: []string{"--file"},
+			err:  "flag needs an argument: --file",
+		},
+		{
+			name: "unknown shorthand",
+			args: []string{"-xq"},
+			err:  "unknown shorthand flag: -q in -xq",
+		},
+		{
+			name: "unknown flag",
+			args: []string{"--nope"},
+			err:  "unknown flag: --nope",
+		},
+		{
+			name: "value for negation",
+			args: []string{"--no-color=1"},
+			err:  "flag does not take an argument: --no-color",
+		},
+	}
+
+	app, err := New(&parseCmd{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, pos, rest, err := parseArgs(tt.args, app.RootNode.Flags)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("parseArgs() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseArgs() error = %v", err)
+			}
+			if !maps.EqualFunc(flags, tt.flags, slices.Equal) {
+				t.Errorf("flags = %q, want %q", flags, tt.flags)
+			}
+			if !slices.Equal(pos, tt.pos) {
+				t.Errorf("positionals = %q, want %q", pos, tt.pos)
+			}
+			if !slices.Equal(rest, tt.rest) {
+				t.Errorf("rest = %q, want %q", rest, tt.rest)
+			}
+		})
+	}
+}
+
+type greedyCmd struct {
+	Host  string `arg:""`
+	Ports []int  `arg:""`
+}
+
+func (c *greedyCmd) Run() error { return nil }
+
+type greedyDurationCmd struct {
+	Delays []time.Duration `arg:""`
+}
+
+func (c *greedyDurationCmd) Run() error { return nil }
+
+func TestGreedyArgs(t *testing.T) {
+	cmd := &greedyCmd{}
+	app := newTestApp(t, cmd)
+	if err := app.RunWithArgs(context.Background(), []string{"example.com", "80", "443"}); err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Host != "example.com" || !slices.Equal(cmd.Ports, []int{80, 443}) {
+		t.Errorf("host = %q, ports = %v", cmd.Host, cmd.Ports)
+	}
+
+	err := newTestApp(t, &greedyCmd{}).RunWithArgs(context.Background(), []string{"example.com", "80", "http"})
+	want := "invalid value for argument ports: element 1: invalid integer: http"
+	if err == nil || err.Error() != want {
+		t.Errorf("RunWithArgs() error = %v, want %q", err, want)
+	}
+
+	dcmd := &greedyDurationCmd{}
+	if err := newTestApp(t, dcmd).RunWithArgs(context.Background(), []string{"1s", "2m"}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(dcmd.Delays, []time.Duration{time.Second, 2 * time.Minute}) {
+		t.Errorf("delays = %v", dcmd.Delays)
+	}
+
+	err = newTestApp(t, &greedyDurationCmd{}).RunWithArgs(context.Background(), []string{"1s", "later"})
+	want = "invalid value for argument delays: element 1: invalid duration: later"
+	if err == nil || err.Error() != want {
+		t.Errorf("RunWithArgs() error = %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
+)
+
+type rerunCmd struct {
+	N     int               `cli:"n"`
+	Tags  []string          `cli:"tag"`
+	M     map[string]string `cli:"m"`
+	Files []string          `arg:"" help:"files"`
+}
+
+func (c *rerunCmd) Run() error { return nil }
+
+func TestRerunRestoresInitialValues(t *testing.T) {
+	cmd := &rerunCmd{N: 7, Tags: []string{"init"}}
+	app := newTestApp(t, cmd)
+
+	args := []string{"--n", "3", "--tag", "a", "--m", "k=v", "x"}
+	if err := app.RunWithArgs(context.Background(), args); err != nil {
+		t.Fatal(err)
+	}
+	want := rerunCmd{N: 3, Tags: []string{"a"}, M: map[string]string{"k": "v"}, Files: []string{"x"}}
+	if !reflect.DeepEqual(*cmd, want) {
+		t.Fatalf("first run bound %+v, want %+v", *cmd, want)
+	}
+
+	if err := app.RunWithArgs(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	want = rerunCmd{N: 7, Tags: []string{"init"}}
+	if !reflect.DeepEqual(*cmd, want) {
+		t.Errorf("second run bound %+v, want %+v", *cmd, want)
+	}
+	if origin, _ := app.FlagOrigin("n"); origin.Source != resolver.SourceNone {
+		t.Errorf("FlagOrigin(n) = %v, want none", origin)
+	}
+}
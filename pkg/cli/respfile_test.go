@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type respfileCmd struct {
+	Body    string `cli:"body,b" from:"file"`
+	Region  string `cli:"region,r"`
+	Verbose bool   `cli:"verbose,v"`
+}
+
+func (c *respfileCmd) Run() error { return nil }
+
+func TestResponseFileFlagValues(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	files := map[string]string{
+		"payload.json": `{"name": "my app"}`,
+		"args.rsp":     "--verbose --region eu\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want respfileCmd
+		err  string
+	}{
+		{
+			name: "file value",
+			args: []string{"--body", "@payload.json", "@args.rsp"},
+			want: respfileCmd{Body: `{"name": "my app"}`, Region: "eu", Verbose: true},
+		},
+		{
+			name: "short file value",
+			args: []string{"-vb", "@payload.json"},
+			want: respfileCmd{Body: `{"name": "my app"}`, Verbose: true},
+		},
+		{
+			name: "value",
+			args: []string{"--region", "@args.rsp"},
+			err:  "cannot expand response file @args.rsp as the value of --region (escape it as @@args.rsp)",
+		},
+		{
+			name: "short value",
+			args: []string{"-vr", "@args.rsp"},
+			err:  "cannot expand response file @args.rsp as the value of -vr (escape it as @@args.rsp)",
+		},
+		{
+			name: "escaped value",
+			args: []string{"-r", "@@eu"},
+			want: respfileCmd{Region: "@eu"},
+		},
+		{
+			name: "attached value",
+			args: []string{"-r@eu"},
+			want: respfileCmd{Region: "@eu"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &respfileCmd{}
+			app := newTestApp(t, cmd, WithResponseFiles())
+
+			err := app.RunWithArgs(context.Background(), tt.args)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("RunWithArgs() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RunWithArgs() error = %v", err)
+			}
+			if *cmd != tt.want {
+				t.Errorf("bound %+v, want %+v", *cmd, tt.want)
+			}
+		})
+	}
+}
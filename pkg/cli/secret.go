@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/log"
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/parser"
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
+)
+
+// secretFileSuffix is appended to the name of a secret flag to form the flag
+// reading its value from a file, e.g. --token-file.
+const secretFileSuffix = "-file"
+
+// takeSecretFiles removes the --<name>-file flags of secret flags from args and
+// returns the file given for each secret flag.
+func takeSecretFiles(args []string, effectiveFlags map[string]*parser.FlagMetadata) ([]string, map[string]string, error) {
+	files := make(map[string]string)
+	for name, meta := range effectiveFlags {
+		if !meta.Secret {
+			continue
+		}
+		rest, path, ok, err := takeBuiltinFlag(args, name+secretFileSuffix, true, effectiveFlags)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			files[name] = path
+		}
+		args = rest
+	}
+	return args, files, nil
+}
+
+// checkSecretFiles reports an error when a secret flag is given both directly
+// and through its file flag.
+func checkSecretFiles(flags map[string][]string, files map[string]string) error {
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if len(flags[name]) > 0 {
+			return fmt.Errorf("flags --%s and --%s%s cannot be used together", name, name, secretFileSuffix)
+		}
+	}
+	return nil
+}
+
+// secretFileProvider returns a Provider reading secret flags from the files
+// given with their --<name>-file flag, up to maxSize bytes.
+func secretFileProvider(files map[string]string, maxSize int64) resolver.Provider {
+	r := &inputReader{maxSize: maxSize}
+	return resolver.ProviderFunc(func(q resolver.Query) ([]string, resolver.Origin, bool, error) {
+		path, ok := files[q.Flag]
+		if !ok {
+			return nil, resolver.Origin{}, false, nil
+		}
+		content, err := r.readFile(path)
+		if err != nil {
+			return nil, resolver.Origin{}, false, fmt.Errorf("cannot read secret file: %w", err)
+		}
+		origin := resolver.Origin{Source: resolver.SourceFlag, Name: "--" + q.Flag + secretFileSuffix, File: path}
+		return []string{trimNewline(content)}, origin, true, nil
+	})
+}
+
+// warnSecretFlags warns about secret flags whose value was passed on the
+// command line, where other users may read it from the process list. Values
+// read from stdin or a file are not secrets themselves.
+func warnSecretFlags(flags map[string][]string, effectiveFlags map[string]*parser.FlagMetadata, logger log.Logger) {
+	for _, name := range slices.Sorted(maps.Keys(flags)) {
+		meta, ok := effectiveFlags[name]
+		if !ok || !meta.Secret {
+			continue
+		}
+		if !slices.ContainsFunc(flags[name], func(v string) bool { return !readsInput(meta, v) }) {
+			continue
+		}
+		hint := fmt.Sprintf("--%s%s or --%s -", name, secretFileSuffix, name)
+		if meta.Env != "" && meta.Env != "-" {
+			hint = meta.Env + ", " + hint
+		}
+		logger.Warning("--%s was passed on the command line, where other users may see it; prefer %s", name, hint)
+	}
+}
+
+// maskArgs returns a copy of args where the values given to secret flags on
+// the command line are masked, either as a whole argument (--token value) or
+// attached to their flag (--token=value, -tvalue).
+func maskArgs(args []string, flags map[string][]string, effectiveFlags map[string]*parser.FlagMetadata) []string {
+	var secrets []string
+	for name, values := range flags {
+		meta, ok := effectiveFlags[name]
+		if !ok || !meta.Secret {
+			continue
+		}
+		for _, v := range values {
+			if v != "" && !readsInput(meta, v) {
+				secrets = append(secrets, v)
+			}
+		}
+	}
+	if len(secrets) == 0 {
+		return args
+	}
+
+	out := slices.Clone(args)
+	for i, arg := range out {
+		for _, secret := range secrets {
+			if arg == secret {
+				out[i] = resolver.SecretMask
+				break
+			}
+			flag, ok := strings.CutSuffix(arg, secret)
+			if ok && strings.HasPrefix(flag, "-") && (strings.HasSuffix(flag, "=") || !strings.HasPrefix(flag, "--")) {
+				out[i] = flag + resolver.SecretMask
+				break
+			}
+		}
+	}
+	return out
+}
+
+// maskSecret replaces the values of a secret flag in an error message.
+func maskSecret(err error, values []string) error {
+	msg := err.Error()
+	for _, v := range values {
+		if v != "" {
+			msg = strings.ReplaceAll(msg, v, resolver.SecretMask)
+		}
+	}
+	return errors.New(msg)
+}
+
+// secretValues returns the values of the secret flags that were set, to be
+// redacted from the logs. Zero values are left out.
+func secretValues(flags map[string]*parser.FlagMetadata) []string {
+	var secrets []string
+	add := func(val reflect.Value) {
+		if val.IsZero() {
+			return
+		}
+		s := fmt.Sprint(val.Interface())
+		if val.Kind() == reflect.String {
+			s = val.String()
+		}
+		secrets = append(secrets, s)
+	}
+
+	for _, meta := range flags {
+		if !meta.Secret || meta.Origin.Source == resolver.SourceNone {
+			continue
+		}
+		val := meta.Field
+		if val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				continue
+			}
+			val = val.Elem()
+		}
+		if val.Kind() == reflect.Slice {
+			for i := 0; i < val.Len(); i++ {
+				add(val.Index(i))
+			}
+			continue
+		}
+		add(val)
+	}
+	return secrets
+}
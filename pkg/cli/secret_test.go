@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type secretCmd struct {
+	Base
+	Token string   `cli:"token" env:"SECRET_TEST_TOKEN" secret:"true"`
+	Pin   int      `cli:"pin" secret:"true"`
+	Keys  []string `cli:"key" secret:"true"`
+}
+
+func (c *secretCmd) Run() error {
+	c.Logger.Info("port %d, token=%s, keys %v", 8080, c.Token, c.Keys)
+	return nil
+}
+
+func TestSecretRedaction(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		token string
+		want  string
+	}{
+		{
+			name: "unset",
+			want: "port 8080, token=, keys []",
+		},
+		{
+			name:  "set",
+			args:  []string{"--pin", "80", "--key", "ab", "--key", "s3cr3t-key"},
+			token: "abc",
+			want:  "port 8080, token=********, keys [******** ********]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SECRET_TEST_TOKEN", tt.token)
+
+			var out bytes.Buffer
+			app := newTestApp(t, &secretCmd{})
+			app.Stdout = &out
+
+			if err := app.RunWithArgs(context.Background(), tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("logged %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+type secretFileCmd struct {
+	Token string `cli:"token" secret:"true" from:"file"`
+}
+
+func (c *secretFileCmd) Run() error { return nil }
+
+func TestSecretFlagWarning(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tok.txt")
+	if err := os.WriteFile(path, []byte("s3cr3t\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		warn bool
+	}{
+		{name: "value", args: []string{"--token", "s3cr3t"}, warn: true},
+		{name: "file", args: []string{"--token", "@" + path}},
+		{name: "stdin", args: []string{"--token", "-"}},
+		{name: "file flag", args: []string{"--token-file", path}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &secretFileCmd{}
+			app := newTestApp(t, cmd)
+			var stderr bytes.Buffer
+			app.Stdin, app.Stderr = strings.NewReader("s3cr3t\n"), &stderr
+
+			if err := app.RunWithArgs(context.Background(), tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if cmd.Token != "s3cr3t" {
+				t.Errorf("Token = %q, want s3cr3t", cmd.Token)
+			}
+			if warned := strings.Contains(stderr.String(), "--token was passed on the command line"); warned != tt.warn {
+				t.Errorf("warned = %v, want %v: %q", warned, tt.warn, stderr.String())
+			}
+		})
+	}
+}
+
+type secretArgsCmd struct {
+	Token string `cli:"token,t" secret:"true"`
+	Key   string `cli:"key" secret:"true"`
+	Name  string `cli:"name"`
+}
+
+func (c *secretArgsCmd) Run() error { return nil }
+
+func TestInvocationArgsMasked(t *testing.T) {
+	app := newTestApp(t, &secretArgsCmd{})
+
+	var got []string
+	app.Use(func(next Handler) Handler {
+		return func(ctx context.Context, inv *Invocation) error {
+			got = inv.Args
+			return next(ctx, inv)
+		}
+	})
+
+	args := []string{"--token", "abc", "--key=xyz", "--name", "abcd", "-tabc"}
+	if err := app.RunWithArgs(context.Background(), args); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"--token", "********", "--key=********", "--name", "abcd", "-t********"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Invocation.Args = %q, want %q", got, want)
+	}
+	if args[1] != "abc" {
+		t.Errorf("the arguments of the caller were modified: %q", args)
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ErrInterrupted is the cause of the cancellation of the context injected into
+// commands when the application receives SIGINT or SIGTERM.
+//
+// Example:
+//
+//	if errors.Is(context.Cause(c.Ctx), cli.ErrInterrupted) {
+//		c.Logger.Warning("interrupted, cleaning up")
+//	}
+var ErrInterrupted = errors.New("interrupted")
+
+// signalContext returns a context derived from parent which is cancelled on the
+// first SIGINT or SIGTERM, giving commands the chance to stop gracefully. A
+// second signal exits the process immediately. The returned function releases
+// the signal handler and must be called once the run is over.
+func signalContext(parent context.Context, stderr io.Writer) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(parent)
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-signals:
+			cancel(ErrInterrupted)
+		case <-done:
+			return
+		}
+
+		select {
+		case sig := <-signals:
+			fmt.Fprintf(stderr, "Received %s again, exiting\n", sig)
+			os.Exit(signalExitCode(sig))
+		case <-done:
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		close(done)
+		cancel(nil)
+	}
+}
+
+// signalExitCode returns the conventional exit code of a process terminated
+// by a signal, 128 plus the signal number.
+func signalExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
+)
+
+// Extensions lists the supported configuration file extensions, in the order
+// they are probed when searching for a file.
+var Extensions = []string{".json", ".toml", ".yaml", ".yml", ".ini"}
+
+// entry is a flattened configuration value with the line it was defined on.
+type entry struct {
+	values []string
+	line   int
+}
+
+// File holds the flattened values of a configuration file. Nested keys are
+// joined with dots, e.g. the TOML key port in table [server] becomes server.port.
+type File struct {
+	Path    string
+	entries map[string]entry
+}
+
+// Load reads and parses a configuration file, choosing the format from its extension.
+//
+// Example:
+//
+//	f, err := config.Load("/etc/mytool/config.toml")
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(path, data)
+}
+
+// Parse parses configuration data, choosing the format from the path extension.
+// Syntax errors are reported as path:line: message.
+//
+// Example:
+//
+//	f, err := config.Parse("config.json", []byte(`{"port": 8080}`))
+func Parse(path string, data []byte) (*File, error) {
+	f := &File{Path: path, entries: make(map[string]entry)}
+
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = parseJSON(f, data)
+	case ".toml":
+		err = parseTOML(f, data)
+	case ".yaml", ".yml":
+		err = parseYAML(f, data)
+	case ".ini":
+		err = parseINI(f, data)
+	default:
+		return nil, fmt.Errorf("%s: unsupported configuration format", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Lookup returns the values stored under key and the line they were defined on.
+// When key is a table, its entries are returned as key=value pairs so they can
+// be bound to map flags.
+func (f *File) Lookup(key string) ([]string, int, bool) {
+	if e, ok := f.entries[key]; ok {
+		return e.values, e.line, true
+	}
+
+	prefix := key + "."
+	var subKeys []string
+	for k := range f.entries {
+		if strings.HasPrefix(k, prefix) {
+			subKeys = append(subKeys, k)
+		}
+	}
+	if len(subKeys) == 0 {
+		return nil, 0, false
+	}
+	sort.Strings(subKeys)
+
+	var values []string
+	line := 0
+	for _, k := range subKeys {
+		e := f.entries[k]
+		for _, v := range e.values {
+			values = append(values, strings.TrimPrefix(k, prefix)+"="+v)
+		}
+		if line == 0 || e.line < line {
+			line = e.line
+		}
+	}
+	return values, line, true
+}
+
+// set stores a value, failing if the key was already defined.
+func (f *File) set(key string, values []string, line int) error {
+	if prev, ok := f.entries[key]; ok {
+		return f.errorf(line, "duplicate key %q (first defined on line %d)", key, prev.line)
+	}
+	f.entries[key] = entry{values: values, line: line}
+	return nil
+}
+
+// errorf formats an error pointing at a line of the file.
+func (f *File) errorf(line int, format string, a ...any) error {
+	return fmt.Errorf("%s:%d: %s", f.Path, line, fmt.Sprintf(format, a...))
+}
+
+// Config is an ordered set of configuration files. Files added later take
+// precedence, a key is read from the last file defining it.
+type Config struct {
+	Files []*File
+}
+
+// Add appends a file with a higher precedence than the ones already added.
+func (c *Config) Add(f *File) {
+	c.Files = append(c.Files, f)
+}
+
+// Lookup returns the values stored under key in the file with the highest
+// precedence defining it, along with their origin.
+//
+// Example:
+//
+//	vals, origin, ok := cfg.Lookup("server.port")
+func (c *Config) Lookup(key string) ([]string, resolver.Origin, bool) {
+	if c == nil {
+		return nil, resolver.Origin{}, false
+	}
+	for i := len(c.Files) - 1; i >= 0; i-- {
+		f := c.Files[i]
+		if values, line, ok := f.Lookup(key); ok {
+			return values, resolver.Origin{
+				Source: resolver.SourceConfig,
+				File:   f.Path,
+				Key:    key,
+				Line:   line,
+			}, true
+		}
+	}
+	return nil, resolver.Origin{}, false
+}
+
+// SearchPaths returns the default locations of the configuration file of an
+// application, without extension and from the lowest to the highest precedence:
+// system wide, user (XDG) and project local.
+//
+// Example:
+//
+//	config.SearchPaths("mytool")
+//	// [/etc/mytool/config ~/.config/mytool/config .mytool]
+func SearchPaths(name string) []string {
+	paths := []string{filepath.Join("/etc", name, "config")}
+
+	userDir := os.Getenv("XDG_CONFIG_HOME")
+	if userDir == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			userDir = filepath.Join(home, ".config")
+		}
+	}
+	if userDir != "" {
+		paths = append(paths, filepath.Join(userDir, name, "config"))
+	}
+
+	return append(paths, "."+name)
+}
+
+// Discover loads the configuration files found in the given locations, in order.
+// Each location is either a file with a supported extension or a path without
+// extension, in which case the first existing path+extension is used. Missing
+// files are skipped.
+//
+// Example:
+//
+//	cfg, err := config.Discover(config.SearchPaths("mytool")...)
+func Discover(paths ...string) (*Config, error) {
+	cfg := &Config{}
+	for _, p := range paths {
+		path, ok := find(p)
+		if !ok {
+			continue
+		}
+		f, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Add(f)
+	}
+	return cfg, nil
+}
+
+// find resolves a search location to an existing file.
+func find(p string) (string, bool) {
+	candidates := []string{p}
+	if !hasKnownExt(p) {
+		candidates = candidates[:0]
+		for _, ext := range Extensions {
+			candidates = append(candidates, p+ext)
+		}
+	}
+
+	for _, c := range candidates {
+		info, err := os.Stat(c)
+		if err == nil && !info.IsDir() {
+			return c, true
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			// Unreadable locations are reported by Load.
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// hasKnownExt reports whether the path ends with a supported extension.
+func hasKnownExt(p string) bool {
+	ext := strings.ToLower(filepath.Ext(p))
+	for _, e := range Extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
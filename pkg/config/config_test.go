@@ -0,0 +1,78 @@
+package config
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+// parseTest is a configuration document along with the flattened values it
+// must produce, or the error it must fail with.
+type parseTest struct {
+	name string
+	data string
+	want map[string][]string
+	err  string
+}
+
+// runParseTests parses every test document as the file at path.
+func runParseTests(t *testing.T, path string, tests []parseTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse(path, []byte(tt.data))
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("Parse() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			got := make(map[string][]string, len(f.entries))
+			for key, e := range f.entries {
+				got[key] = e.values
+			}
+			if !maps.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("Parse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupTable(t *testing.T) {
+	f, err := Parse("config.toml", []byte("[labels]\nenv = \"prod\"\ntier = \"web\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, line, ok := f.Lookup("labels")
+	if !ok || line != 2 || !slices.Equal(values, []string{"env=prod", "tier=web"}) {
+		t.Errorf("Lookup() = %q, %d, %v", values, line, ok)
+	}
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	low, _ := Parse("/etc/tool/config.toml", []byte("port = 80\nhost = \"a\"\n"))
+	high, _ := Parse(".tool.toml", []byte("\nport = 8080\n"))
+	cfg := &Config{}
+	cfg.Add(low)
+	cfg.Add(high)
+
+	values, origin, ok := cfg.Lookup("port")
+	if !ok || !slices.Equal(values, []string{"8080"}) || origin.File != ".tool.toml" || origin.Line != 2 {
+		t.Errorf("Lookup(port) = %q, %+v, %v", values, origin, ok)
+	}
+	values, origin, ok = cfg.Lookup("host")
+	if !ok || !slices.Equal(values, []string{"a"}) || origin.File != "/etc/tool/config.toml" {
+		t.Errorf("Lookup(host) = %q, %+v, %v", values, origin, ok)
+	}
+}
+
+func TestParseUnsupportedFormat(t *testing.T) {
+	if _, err := Parse("config.xml", nil); err == nil || err.Error() != "config.xml: unsupported configuration format" {
+		t.Errorf("Parse() error = %v", err)
+	}
+}
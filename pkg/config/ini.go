@@ -0,0 +1,62 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// parseINI flattens an INI document. Keys inside a [section] are prefixed with
+// the section name; repeating a key collects multiple values.
+func parseINI(f *File, data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	section := ""
+	lineNo := 0
+
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return f.errorf(lineNo, "unterminated section header")
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if section == "" {
+				return f.errorf(lineNo, "empty section name")
+			}
+			continue
+		}
+
+		sepIdx := strings.IndexAny(line, "=:")
+		if sepIdx < 0 {
+			return f.errorf(lineNo, "expected key = value")
+		}
+
+		key := strings.TrimSpace(line[:sepIdx])
+		if key == "" {
+			return f.errorf(lineNo, "missing key")
+		}
+		if section != "" {
+			key = section + "." + key
+		}
+
+		value, err := unquoteScalar(strings.TrimSpace(line[sepIdx+1:]))
+		if err != nil {
+			return f.errorf(lineNo, "%v", err)
+		}
+
+		if e, ok := f.entries[key]; ok {
+			e.values = append(e.values, value)
+			f.entries[key] = e
+			continue
+		}
+		f.entries[key] = entry{values: []string{value}, line: lineNo}
+	}
+
+	return scanner.Err()
+}
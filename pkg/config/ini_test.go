@@ -0,0 +1,58 @@
+package config
+
+import "testing"
+
+func TestParseINI(t *testing.T) {
+	runParseTests(t, "config.ini", []parseTest{
+		{
+			name: "scalars",
+			data: "port = 8080\nhost: example.com\n",
+			want: map[string][]string{"port": {"8080"}, "host": {"example.com"}},
+		},
+		{
+			name: "nesting",
+			data: "name = x\n[server]\nhost = a\n[server.tls]\ncert = c.pem\n",
+			want: map[string][]string{"name": {"x"}, "server.host": {"a"}, "server.tls.cert": {"c.pem"}},
+		},
+		{
+			name: "repeated keys",
+			data: "[deploy]\ntag = a\ntag = b\n",
+			want: map[string][]string{"deploy.tag": {"a", "b"}},
+		},
+		{
+			name: "quoting",
+			data: "a = \"x ; y\"\nb = 'it''s'\nc = a=b\n",
+			want: map[string][]string{"a": {"x ; y"}, "b": {"it's"}, "c": {"a=b"}},
+		},
+		{
+			name: "comments",
+			data: "; comment\n# comment\n\nport = 80\n",
+			want: map[string][]string{"port": {"80"}},
+		},
+		{
+			name: "unterminated section",
+			data: "a = 1\n[server\n",
+			err:  "config.ini:2: unterminated section header",
+		},
+		{
+			name: "empty section",
+			data: "[ ]\n",
+			err:  "config.ini:1: empty section name",
+		},
+		{
+			name: "missing key",
+			data: "a = 1\n= 2\n",
+			err:  "config.ini:2: missing key",
+		},
+		{
+			name: "missing separator",
+			data: "a = 1\nb\n",
+			err:  "config.ini:2: expected key = value",
+		},
+		{
+			name: "unterminated string",
+			data: "a = \"open\n",
+			err:  `config.ini:1: unterminated string "open`,
+		},
+	})
+}
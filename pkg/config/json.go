@@ -0,0 +1,117 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// parseJSON flattens a JSON document whose root is an object.
+func parseJSON(f *File, data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	lineAt := func(offset int64) int {
+		return bytes.Count(data[:min(int(offset), len(data))], []byte("\n")) + 1
+	}
+
+	wrap := func(err error) error {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return f.errorf(lineAt(syntaxErr.Offset), "%v", err)
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return f.errorf(lineAt(int64(len(data))), "unexpected end of JSON input")
+		}
+		return f.errorf(lineAt(dec.InputOffset()), "%v", err)
+	}
+
+	tok, err := dec.Token()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return wrap(err)
+	}
+	if tok != json.Delim('{') {
+		return f.errorf(lineAt(dec.InputOffset()), "root must be an object")
+	}
+
+	var walkObject func(prefix string) error
+	var readValue func(key string, line int) ([]string, error)
+
+	walkObject = func(prefix string) error {
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return wrap(err)
+			}
+			key := prefix + fmt.Sprint(tok)
+			line := lineAt(dec.InputOffset())
+
+			values, err := readValue(key, line)
+			if err != nil {
+				return err
+			}
+			if values != nil {
+				if err := f.set(key, values, line); err != nil {
+					return err
+				}
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return wrap(err)
+		}
+		return nil
+	}
+
+	// readValue reads the value of key. Objects are flattened in place and
+	// return nil, arrays of scalars return one value per element.
+	readValue = func(key string, line int) ([]string, error) {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, wrap(err)
+		}
+
+		switch tok {
+		case json.Delim('{'):
+			return nil, walkObject(key + ".")
+		case json.Delim('['):
+			values := []string{}
+			for dec.More() {
+				tok, err := dec.Token()
+				if err != nil {
+					return nil, wrap(err)
+				}
+				if d, ok := tok.(json.Delim); ok {
+					return nil, f.errorf(lineAt(dec.InputOffset()), "unsupported nested %q in array %q", d, key)
+				}
+				values = append(values, jsonScalar(tok))
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, wrap(err)
+			}
+			return values, nil
+		default:
+			return []string{jsonScalar(tok)}, nil
+		}
+	}
+
+	if err := walkObject(""); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return f.errorf(lineAt(dec.InputOffset()), "unexpected data after root object")
+	}
+	return nil
+}
+
+// jsonScalar renders a scalar JSON token as a flag value.
+func jsonScalar(tok json.Token) string {
+	if tok == nil {
+		return ""
+	}
+	return fmt.Sprint(tok)
+}
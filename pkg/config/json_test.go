@@ -0,0 +1,63 @@
+package config
+
+import "testing"
+
+func TestParseJSON(t *testing.T) {
+	runParseTests(t, "config.json", []parseTest{
+		{
+			name: "scalars",
+			data: `{"port": 8080, "host": "example.com", "debug": true, "none": null}`,
+			want: map[string][]string{"port": {"8080"}, "host": {"example.com"}, "debug": {"true"}, "none": {""}},
+		},
+		{
+			name: "empty",
+			data: "",
+			want: map[string][]string{},
+		},
+		{
+			name: "nesting",
+			data: `{"server": {"host": "a", "tls": {"cert": "c.pem"}}, "name": "x"}`,
+			want: map[string][]string{"server.host": {"a"}, "server.tls.cert": {"c.pem"}, "name": {"x"}},
+		},
+		{
+			name: "lists",
+			data: `{"ports": [80, 443], "tags": ["a", "b c"], "none": []}`,
+			want: map[string][]string{"ports": {"80", "443"}, "tags": {"a", "b c"}, "none": {}},
+		},
+		{
+			name: "quoting",
+			data: `{"a": "x \"y\"", "b": "tab\there", "c.d": "e"}`,
+			want: map[string][]string{"a": {`x "y"`}, "b": {"tab\there"}, "c.d": {"e"}},
+		},
+		{
+			name: "duplicate key",
+			data: "{\n  \"port\": 80,\n  \"port\": 81\n}",
+			err:  `config.json:3: duplicate key "port" (first defined on line 2)`,
+		},
+		{
+			name: "root not an object",
+			data: `[1, 2]`,
+			err:  "config.json:1: root must be an object",
+		},
+		{
+			name: "nested array",
+			data: "{\n  \"a\": [[1]]\n}",
+			err:  `config.json:2: unsupported nested "[" in array "a"`,
+		},
+		{
+			name: "syntax error",
+			data: "{\n  \"a\": 1,\n  \"b\" 2\n}",
+			err:  "config.json:3: invalid character '2' after object key",
+		},
+		{
+			name: "truncated",
+			data: "{\n  \"a\": 1,\n",
+			err:  "config.json:3: unexpected end of JSON input",
+		},
+		{
+			name: "trailing data",
+			data: `{"a": 1} {}`,
+			err:  "config.json:1: unexpected data after root object",
+		},
+	})
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// parseTOML flattens a TOML document. It supports tables, dotted keys, basic
+// and literal strings, scalars, arrays of scalars (possibly spanning lines) and
+// inline tables. Arrays of tables and multi-line strings are not supported.
+func parseTOML(f *File, data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	table := ""
+	lineNo := 0
+
+	for scanner.Scan() {
+		lineNo++
+		line := stripComment(strings.TrimSpace(scanner.Text()), '#')
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[[") {
+			return f.errorf(lineNo, "arrays of tables are not supported")
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return f.errorf(lineNo, "unterminated table header")
+			}
+			key, err := parseTOMLKey(line[1 : len(line)-1])
+			if err != nil {
+				return f.errorf(lineNo, "%v", err)
+			}
+			table = key
+			continue
+		}
+
+		eq := indexUnquoted(line, "=")
+		if eq < 0 {
+			return f.errorf(lineNo, "expected key = value")
+		}
+
+		key, err := parseTOMLKey(line[:eq])
+		if err != nil {
+			return f.errorf(lineNo, "%v", err)
+		}
+		if table != "" {
+			key = table + "." + key
+		}
+
+		value := strings.TrimSpace(line[eq+1:])
+		startLine := lineNo
+
+		// Arrays and inline tables may span multiple lines.
+		for bracketDepth(value) > 0 && scanner.Scan() {
+			lineNo++
+			value += " " + stripComment(strings.TrimSpace(scanner.Text()), '#')
+		}
+		if bracketDepth(value) != 0 {
+			return f.errorf(startLine, "unterminated value for %q", key)
+		}
+
+		if err := setTOMLValue(f, key, value, startLine); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// setTOMLValue parses a TOML value and stores it under key.
+func setTOMLValue(f *File, key, value string, line int) error {
+	switch {
+	case value == "":
+		return f.errorf(line, "missing value for %q", key)
+	case strings.HasPrefix(value, `"""`) || strings.HasPrefix(value, "'''"):
+		return f.errorf(line, "multi-line strings are not supported")
+	case strings.HasPrefix(value, "["):
+		values, err := parseFlowList(value)
+		if err != nil {
+			return f.errorf(line, "%v", err)
+		}
+		return f.set(key, values, line)
+	case strings.HasPrefix(value, "{"):
+		inner := strings.TrimSpace(value[1 : len(value)-1])
+		for _, pair := range splitUnquoted(inner, ',') {
+			if pair == "" {
+				continue
+			}
+			eq := indexUnquoted(pair, "=")
+			if eq < 0 {
+				return f.errorf(line, "expected key = value in inline table")
+			}
+			subKey, err := parseTOMLKey(pair[:eq])
+			if err != nil {
+				return f.errorf(line, "%v", err)
+			}
+			if err := setTOMLValue(f, key+"."+subKey, strings.TrimSpace(pair[eq+1:]), line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	v, err := unquoteScalar(value)
+	if err != nil {
+		return f.errorf(line, "%v", err)
+	}
+	return f.set(key, []string{v}, line)
+}
+
+// parseTOMLKey parses a possibly dotted and quoted key into its flattened form.
+func parseTOMLKey(s string) (string, error) {
+	parts := []string{}
+	for _, part := range splitUnquoted(strings.TrimSpace(s), '.') {
+		p, err := unquoteScalar(part)
+		if err != nil {
+			return "", err
+		}
+		if p == "" {
+			return "", errEmptyKey
+		}
+		parts = append(parts, p)
+	}
+	if len(parts) == 0 {
+		return "", errEmptyKey
+	}
+	return strings.Join(parts, "."), nil
+}
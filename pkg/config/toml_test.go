@@ -0,0 +1,68 @@
+package config
+
+import "testing"
+
+func TestParseTOML(t *testing.T) {
+	runParseTests(t, "config.toml", []parseTest{
+		{
+			name: "scalars",
+			data: "port = 8080\nhost = \"example.com\"\ndebug = true\n",
+			want: map[string][]string{"port": {"8080"}, "host": {"example.com"}, "debug": {"true"}},
+		},
+		{
+			name: "nesting",
+			data: "name = \"x\"\n[server]\nhost = \"a\"\ntls.cert = \"c.pem\"\n[server.limits]\nmax = 10\n",
+			want: map[string][]string{"name": {"x"}, "server.host": {"a"}, "server.tls.cert": {"c.pem"}, "server.limits.max": {"10"}},
+		},
+		{
+			name: "lists",
+			data: "ports = [80, 443]\ntags = [\n  \"a\", # first\n  \"b\",\n]\nlabels = { env = \"prod\", tier = \"web\" }\n",
+			want: map[string][]string{
+				"ports":       {"80", "443"},
+				"tags":        {"a", "b"},
+				"labels.env":  {"prod"},
+				"labels.tier": {"web"},
+			},
+		},
+		{
+			name: "quoting",
+			data: "a = \"x # y\"\nb = 'C:\\path'\nc = \"tab\\there\"\n\"d.e\" = \"f\"\n",
+			want: map[string][]string{"a": {"x # y"}, "b": {`C:\path`}, "c": {"tab\there"}, "d.e": {"f"}},
+		},
+		{
+			name: "comments",
+			data: "# comment\nport = 80 # trailing\n\n[server] # table\nurl = \"http://x/#frag\"\n",
+			want: map[string][]string{"port": {"80"}, "server.url": {"http://x/#frag"}},
+		},
+		{
+			name: "duplicate key",
+			data: "[server]\nport = 80\n[server]\nport = 81\n",
+			err:  `config.toml:4: duplicate key "server.port" (first defined on line 2)`,
+		},
+		{
+			name: "missing value",
+			data: "a = 1\nb =\n",
+			err:  `config.toml:2: missing value for "b"`,
+		},
+		{
+			name: "unterminated array",
+			data: "a = 1\nb = [1,\n2\n",
+			err:  `config.toml:2: unterminated value for "b"`,
+		},
+		{
+			name: "unterminated table",
+			data: "[server\n",
+			err:  "config.toml:1: unterminated table header",
+		},
+		{
+			name: "arrays of tables",
+			data: "[[servers]]\n",
+			err:  "config.toml:1: arrays of tables are not supported",
+		},
+		{
+			name: "missing separator",
+			data: "a = 1\nb\n",
+			err:  "config.toml:2: expected key = value",
+		},
+	})
+}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// yamlParent is a mapping key whose value is the indented block below it.
+type yamlParent struct {
+	indent int
+	key    string
+	child  int  // indentation of the keys below, -1 until the first one
+	items  bool // whether the block is a sequence
+}
+
+// parseYAML flattens a YAML document. It supports nested mappings, block and
+// flow sequences of scalars, flow mappings and quoted scalars. Anchors, tags,
+// block scalars and sequences of mappings are not supported.
+func parseYAML(f *File, data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	var stack []yamlParent
+	rootIndent := -1
+	lineNo := 0
+
+	// defined maps every key written in the document to its line, including
+	// the keys opening a block, which never reach f.set themselves.
+	defined := make(map[string]int)
+
+	for scanner.Scan() {
+		lineNo++
+		raw := scanner.Text()
+		trimmed := strings.TrimSpace(raw)
+
+		if trimmed == "---" || trimmed == "..." {
+			continue
+		}
+
+		content := stripComment(trimmed, '#')
+		if content == "" {
+			continue
+		}
+
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		if strings.HasPrefix(raw[indent:], "\t") {
+			return f.errorf(lineNo, "tabs are not allowed for indentation")
+		}
+
+		if content == "-" || strings.HasPrefix(content, "- ") {
+			// Sequence items may share the indentation of their key.
+			for len(stack) > 0 && stack[len(stack)-1].indent > indent {
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) == 0 {
+				return f.errorf(lineNo, "sequence item without a key")
+			}
+			if stack[len(stack)-1].child >= 0 {
+				return f.errorf(lineNo, "unexpected sequence item in mapping %q", stack[len(stack)-1].key)
+			}
+			stack[len(stack)-1].items = true
+
+			item := strings.TrimSpace(strings.TrimPrefix(content, "-"))
+			if strings.HasPrefix(item, "[") || strings.HasPrefix(item, "{") || indexUnquoted(item, ": ") >= 0 || strings.HasSuffix(item, ":") {
+				return f.errorf(lineNo, "only sequences of scalars are supported")
+			}
+			v, err := yamlScalar(item)
+			if err != nil {
+				return f.errorf(lineNo, "%v", err)
+			}
+
+			key := stack[len(stack)-1].key
+			e, ok := f.entries[key]
+			if !ok {
+				e.line = lineNo
+			}
+			e.values = append(e.values, v)
+			f.entries[key] = e
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		// Keys of a block share its indentation, so a deeper key does not
+		// belong to any block (e.g. when indented under a scalar).
+		if len(stack) == 0 {
+			if rootIndent < 0 {
+				rootIndent = indent
+			}
+			if indent != rootIndent {
+				return f.errorf(lineNo, "unexpected indentation")
+			}
+		} else {
+			parent := &stack[len(stack)-1]
+			if parent.items {
+				return f.errorf(lineNo, "unexpected key in sequence %q", parent.key)
+			}
+			if parent.child < 0 {
+				parent.child = indent
+			}
+			if indent != parent.child {
+				return f.errorf(lineNo, "unexpected indentation")
+			}
+		}
+
+		sep := indexUnquoted(content, ": ")
+		if sep < 0 {
+			if !strings.HasSuffix(content, ":") {
+				return f.errorf(lineNo, "expected key: value")
+			}
+			sep = len(content) - 1
+		}
+
+		key, err := unquoteScalar(strings.TrimSpace(content[:sep]))
+		if err != nil {
+			return f.errorf(lineNo, "%v", err)
+		}
+		if key == "" {
+			return f.errorf(lineNo, "%v", errEmptyKey)
+		}
+		if len(stack) > 0 {
+			key = stack[len(stack)-1].key + "." + key
+		}
+
+		if prev, ok := defined[key]; ok {
+			return f.errorf(lineNo, "duplicate key %q (first defined on line %d)", key, prev)
+		}
+		defined[key] = lineNo
+
+		value := strings.TrimSpace(content[sep+1:])
+		switch {
+		case value == "":
+			stack = append(stack, yamlParent{indent: indent, key: key, child: -1})
+		case strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">"):
+			return f.errorf(lineNo, "block scalars are not supported")
+		case strings.HasPrefix(value, "&") || strings.HasPrefix(value, "*") || strings.HasPrefix(value, "!"):
+			return f.errorf(lineNo, "anchors, aliases and tags are not supported")
+		case strings.HasPrefix(value, "["):
+			if bracketDepth(value) != 0 {
+				return f.errorf(lineNo, "multi-line flow sequences are not supported")
+			}
+			values, err := parseFlowList(value)
+			if err != nil {
+				return f.errorf(lineNo, "%v", err)
+			}
+			if err := f.set(key, values, lineNo); err != nil {
+				return err
+			}
+		case strings.HasPrefix(value, "{"):
+			if bracketDepth(value) != 0 {
+				return f.errorf(lineNo, "multi-line flow mappings are not supported")
+			}
+			inner := strings.TrimSpace(value[1 : len(value)-1])
+			for _, pair := range splitUnquoted(inner, ',') {
+				if pair == "" {
+					continue
+				}
+				colon := indexUnquoted(pair, ":")
+				if colon < 0 {
+					return f.errorf(lineNo, "expected key: value in flow mapping")
+				}
+				subKey, err := unquoteScalar(strings.TrimSpace(pair[:colon]))
+				if err != nil {
+					return f.errorf(lineNo, "%v", err)
+				}
+				v, err := yamlScalar(strings.TrimSpace(pair[colon+1:]))
+				if err != nil {
+					return f.errorf(lineNo, "%v", err)
+				}
+				if err := f.set(key+"."+subKey, []string{v}, lineNo); err != nil {
+					return err
+				}
+			}
+		default:
+			v, err := yamlScalar(value)
+			if err != nil {
+				return f.errorf(lineNo, "%v", err)
+			}
+			if err := f.set(key, []string{v}, lineNo); err != nil {
+				return err
+			}
+		}
+	}
+
+	return scanner.Err()
+}
+
+// yamlScalar unquotes a scalar, mapping null values to an empty string.
+func yamlScalar(s string) (string, error) {
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return "", nil
+	}
+	return unquoteScalar(s)
+}
@@ -0,0 +1,109 @@
+package config
+
+import "testing"
+
+func TestParseYAML(t *testing.T) {
+	runParseTests(t, "config.yaml", []parseTest{
+		{
+			name: "scalars",
+			data: "port: 8080\nhost: example.com\nempty: ~\n",
+			want: map[string][]string{"port": {"8080"}, "host": {"example.com"}, "empty": {""}},
+		},
+		{
+			name: "nesting",
+			data: "server:\n  host: a\n  tls:\n    cert: c.pem\n  port: 80\nname: x\n",
+			want: map[string][]string{"server.host": {"a"}, "server.tls.cert": {"c.pem"}, "server.port": {"80"}, "name": {"x"}},
+		},
+		{
+			name: "lists",
+			data: "tags:\n  - a\n  - \"b c\"\nports: [80, 443]\nitems:\n- x\nlabels: {env: prod, tier: web}\n",
+			want: map[string][]string{
+				"tags":        {"a", "b c"},
+				"ports":       {"80", "443"},
+				"items":       {"x"},
+				"labels.env":  {"prod"},
+				"labels.tier": {"web"},
+			},
+		},
+		{
+			name: "quoting",
+			data: "a: \"x # y\"\nb: 'it''s'\nc: \"tab\\there\"\n\"d e\": f\n",
+			want: map[string][]string{"a": {"x # y"}, "b": {"it's"}, "c": {"tab\there"}, "d e": {"f"}},
+		},
+		{
+			name: "comments",
+			data: "---\n# comment\nport: 80 # trailing\nurl: http://x/#frag\n...\n",
+			want: map[string][]string{"port": {"80"}, "url": {"http://x/#frag"}},
+		},
+		{
+			name: "duplicate key",
+			data: "port: 80\nport: 81\n",
+			err:  `config.yaml:2: duplicate key "port" (first defined on line 1)`,
+		},
+		{
+			name: "duplicate nested key",
+			data: "server:\n  port: 80\n  port: 81\n",
+			err:  `config.yaml:3: duplicate key "server.port" (first defined on line 2)`,
+		},
+		{
+			name: "reopened mapping",
+			data: "a:\n  b: 1\na:\n  c: 2\n",
+			err:  `config.yaml:3: duplicate key "a" (first defined on line 1)`,
+		},
+		{
+			name: "scalar reopened as sequence",
+			data: "tags: a\ntags:\n  - b\n",
+			err:  `config.yaml:2: duplicate key "tags" (first defined on line 1)`,
+		},
+		{
+			name: "sequence redefined as scalar",
+			data: "tags:\n  - a\ntags: b\n",
+			err:  `config.yaml:3: duplicate key "tags" (first defined on line 1)`,
+		},
+		{
+			name: "indented under scalar",
+			data: "port: 80\n  extra: 1\n",
+			err:  "config.yaml:2: unexpected indentation",
+		},
+		{
+			name: "inconsistent indentation",
+			data: "server:\n  host: a\n    port: 80\n",
+			err:  "config.yaml:3: unexpected indentation",
+		},
+		{
+			name: "sequence item in mapping",
+			data: "server:\n  port: 80\n  - x\n",
+			err:  `config.yaml:3: unexpected sequence item in mapping "server"`,
+		},
+		{
+			name: "key in sequence",
+			data: "tags:\n  - a\n  b: c\n",
+			err:  `config.yaml:3: unexpected key in sequence "tags"`,
+		},
+		{
+			name: "sequence item without key",
+			data: "- a\n",
+			err:  "config.yaml:1: sequence item without a key",
+		},
+		{
+			name: "tabs",
+			data: "server:\n\tport: 80\n",
+			err:  "config.yaml:2: tabs are not allowed for indentation",
+		},
+		{
+			name: "unterminated string",
+			data: "a: 1\nb: \"open\n",
+			err:  `config.yaml:2: unterminated string "open`,
+		},
+		{
+			name: "block scalar",
+			data: "a: |\n  text\n",
+			err:  "config.yaml:1: block scalars are not supported",
+		},
+		{
+			name: "missing separator",
+			data: "a: 1\nnot a pair\n",
+			err:  "config.yaml:2: expected key: value",
+		},
+	})
+}
@@ -0,0 +1,231 @@
+package dotenv
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
+)
+
+// Var is a variable read from a dotenv file.
+type Var struct {
+	Value string
+	File  string
+	Line  int
+}
+
+// Env is a lookup layer over the process environment and dotenv files. The
+// process environment is never modified, and its variables take precedence.
+type Env struct {
+	Vars map[string]Var
+	Base resolver.EnvLookup // Replaces the process environment when set
+}
+
+// Load reads the given dotenv files in order; a variable defined in more than
+// one file keeps the last value. Missing files are skipped.
+//
+// Example:
+//
+//	env, err := dotenv.Load(".env", ".env.local")
+func Load(paths ...string) (*Env, error) {
+	return LoadWith(nil, paths...)
+}
+
+// LoadWith is like Load, but layers the files under base instead of the
+// process environment, including for the interpolation of their values.
+//
+// Example:
+//
+//	env, err := dotenv.LoadWith(resolver.MapSource{"HOME": "/tmp"}, ".env")
+func LoadWith(base resolver.EnvLookup, paths ...string) (*Env, error) {
+	env := &Env{Vars: make(map[string]Var), Base: base}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := env.Parse(path, data); err != nil {
+			return nil, err
+		}
+	}
+	return env, nil
+}
+
+// LookupEnv returns the value of a variable from the process environment, or
+// Base when set, falling back to the dotenv files.
+//
+// Example:
+//
+//	val, origin, ok := env.LookupEnv("API_KEY")
+func (e *Env) LookupEnv(name string) (string, resolver.Origin, bool) {
+	if e.Base != nil {
+		if val, origin, ok := e.Base.LookupEnv(name); ok {
+			return val, origin, true
+		}
+	} else if val, ok := os.LookupEnv(name); ok {
+		return val, resolver.Origin{Source: resolver.SourceEnv, Name: name}, true
+	}
+	if v, ok := e.Vars[name]; ok {
+		return v.Value, resolver.Origin{Source: resolver.SourceEnv, Name: name, File: v.File, Line: v.Line}, true
+	}
+	return "", resolver.Origin{}, false
+}
+
+// lookup resolves a variable referenced by an interpolation.
+func (e *Env) lookup(name string) (string, bool) {
+	val, _, ok := e.LookupEnv(name)
+	return val, ok
+}
+
+// Parse parses dotenv data into the layer. Supported syntax:
+//
+//	# comment
+//	export KEY=value # trailing comment
+//	KEY="double quoted, with \n escapes and ${VAR} interpolation"
+//	KEY='single quoted, literal'
+//	KEY=${OTHER:-fallback}/suffix
+//
+// Quoted values may span multiple lines. Errors are reported as path:line: message.
+func (e *Env) Parse(path string, data []byte) error {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// "export" must be followed by whitespace, so exportKEY stays a key.
+		if rest, ok := strings.CutPrefix(line, "export"); ok && strings.TrimLeft(rest, " \t") != rest {
+			line = strings.TrimLeft(rest, " \t")
+		}
+
+		key, rest, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !validName(key) {
+			return fmt.Errorf("%s:%d: expected KEY=value", path, lineNo)
+		}
+		rest = strings.TrimSpace(rest)
+
+		var value string
+		switch {
+		case strings.HasPrefix(rest, `"`) || strings.HasPrefix(rest, "'"):
+			quote := rest[0]
+			body := rest[1:]
+
+			// Collect following lines until the closing quote.
+			end := closingQuote(body, quote)
+			for end < 0 && i+1 < len(lines) {
+				i++
+				body += "\n" + lines[i]
+				end = closingQuote(body, quote)
+			}
+			if end < 0 {
+				return fmt.Errorf("%s:%d: unterminated quoted value for %s", path, lineNo, key)
+			}
+
+			trailing := strings.TrimSpace(body[end+1:])
+			if trailing != "" && !strings.HasPrefix(trailing, "#") {
+				return fmt.Errorf("%s:%d: unexpected characters after quoted value for %s", path, lineNo, key)
+			}
+
+			value = body[:end]
+			if quote == '"' {
+				var err error
+				value, err = expand(unescape(value), e.lookup)
+				if err != nil {
+					return fmt.Errorf("%s:%d: %v", path, lineNo, err)
+				}
+			}
+		default:
+			if idx := strings.Index(rest, " #"); idx >= 0 {
+				rest = strings.TrimSpace(rest[:idx])
+			}
+			var err error
+			value, err = expand(rest, e.lookup)
+			if err != nil {
+				return fmt.Errorf("%s:%d: %v", path, lineNo, err)
+			}
+		}
+
+		e.Vars[key] = Var{Value: value, File: path, Line: lineNo}
+	}
+
+	return nil
+}
+
+// validName reports whether s is a valid variable name.
+func validName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// closingQuote returns the index of the closing quote in s, or -1.
+// Double quotes can be escaped with a backslash.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+// unescape processes the escapes of a double quoted value. An escaped dollar
+// is kept escaped so that expand leaves it literal.
+func unescape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case '$':
+			sb.WriteString(`\$`)
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+// expand replaces $VAR, ${VAR} and ${VAR:-fallback} references using lookup.
+// Undefined variables expand to an empty string, and \$ produces a literal $.
+func expand(s string, lookup func(string) (string, bool)) (string, error) {
+	return resolver.Expand(s, func(name string) (string, bool, error) {
+		if !validName(name) {
+			return "", false, fmt.Errorf("invalid variable reference ${%s}", name)
+		}
+		val, ok := lookup(name)
+		return val, ok, nil
+	})
+}
@@ -0,0 +1,144 @@
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
+)
+
+func TestParse(t *testing.T) {
+	t.Setenv("DOTENV_TEST_HOME", "/home/me")
+
+	tests := []struct {
+		name string
+		data string
+		want map[string]string
+		err  string
+	}{
+		{
+			name: "plain",
+			data: "A=1\nexport B = two \n\nC=\n",
+			want: map[string]string{"A": "1", "B": "two", "C": ""},
+		},
+		{
+			name: "export",
+			data: "export\tA=1\nexport   B=2\nexportC=3\nexport=4\n",
+			want: map[string]string{"A": "1", "B": "2", "exportC": "3", "export": "4"},
+		},
+		{
+			name: "comments",
+			data: "# comment\nA=1 # trailing\nB=x#y\nC=\"v\" # trailing\n",
+			want: map[string]string{"A": "1", "B": "x#y", "C": "v"},
+		},
+		{
+			name: "quoting",
+			data: "A=\"a \\\"b\\\"\\n\\tc\"\nB='$HOME \\n'\nC=\" spaced \"\n",
+			want: map[string]string{"A": "a \"b\"\n\tc", "B": `$HOME \n`, "C": " spaced "},
+		},
+		{
+			name: "multi-line",
+			data: "A=\"line1\nline2\"\nB='x\n\ny'\n",
+			want: map[string]string{"A": "line1\nline2", "B": "x\n\ny"},
+		},
+		{
+			name: "interpolation",
+			data: "BASE=/srv\nA=${BASE}/data\nB=\"$BASE/logs\"\nC=${MISSING:-fallback}\nD=${DOTENV_TEST_HOME}\nE=\\$BASE\nF=\"\\$BASE\"\n",
+			want: map[string]string{
+				"BASE": "/srv",
+				"A":    "/srv/data",
+				"B":    "/srv/logs",
+				"C":    "fallback",
+				"D":    "/home/me",
+				"E":    "$BASE",
+				"F":    "$BASE",
+			},
+		},
+		{
+			name: "duplicates",
+			data: "A=1\nA=2\n",
+			want: map[string]string{"A": "2"},
+		},
+		{
+			name: "missing separator",
+			data: "A=1\nB\n",
+			err:  ".env:2: expected KEY=value",
+		},
+		{
+			name: "invalid name",
+			data: "1A=x\n",
+			err:  ".env:1: expected KEY=value",
+		},
+		{
+			name: "unterminated quote",
+			data: "A=1\nB=\"open\nC=2\n",
+			err:  ".env:2: unterminated quoted value for B",
+		},
+		{
+			name: "trailing characters",
+			data: "A='x' y\n",
+			err:  ".env:1: unexpected characters after quoted value for A",
+		},
+		{
+			name: "invalid reference",
+			data: "A=${B C}\n",
+			err:  ".env:1: invalid variable reference ${B C}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &Env{Vars: make(map[string]Var)}
+			err := env.Parse(".env", []byte(tt.data))
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("Parse() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if len(env.Vars) != len(tt.want) {
+				t.Errorf("Parse() defined %d variables, want %d", len(env.Vars), len(tt.want))
+			}
+			for key, want := range tt.want {
+				if got := env.Vars[key].Value; got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+	if err := os.WriteFile(base, []byte("A=base\nB=base\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(local, []byte("# override\nB=local\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("A", "process")
+
+	env, err := Load(base, filepath.Join(dir, "missing"), local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	val, origin, ok := env.LookupEnv("A")
+	if !ok || val != "process" || origin != (resolver.Origin{Source: resolver.SourceEnv, Name: "A"}) {
+		t.Errorf("LookupEnv(A) = %q, %+v, %v", val, origin, ok)
+	}
+	val, origin, ok = env.LookupEnv("B")
+	if !ok || val != "local" || origin.File != local || origin.Line != 2 {
+		t.Errorf("LookupEnv(B) = %q, %+v, %v", val, origin, ok)
+	}
+	if _, _, ok := env.LookupEnv("DOTENV_TEST_UNDEFINED"); ok {
+		t.Error("LookupEnv(DOTENV_TEST_UNDEFINED) found an undefined variable")
+	}
+}
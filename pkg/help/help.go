@@ -2,22 +2,35 @@ package help
 
 import (
 	"fmt"
+	"reflect"
 	"sort"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/mirkobrombin/go-cli-builder/v2/pkg/parser"
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
 )
 
 // Translator is a function that translates a key.
 type Translator func(string) string
 
-// GenerateHelp generates a formatted help string for a command node.
+// Builtin describes a flag handled by the application rather than bound to a
+// command field, listed after the flags of the command.
+type Builtin struct {
+	Name        string // Flag name, without dashes
+	Placeholder string // Value placeholder, empty for boolean flags
+	Description string
+}
+
+// GenerateHelp generates a formatted help string for a command node, listing
+// the given built-in flags along with the flags of the command.
 //
 // Example:
 //
 //	helpText := help.GenerateHelp(rootNode, nil)
 //	fmt.Println(helpText)
-func GenerateHelp(node *parser.CommandNode, tr Translator) string {
+func GenerateHelp(node *parser.CommandNode, tr Translator, builtins ...Builtin) string {
 	var sb strings.Builder
 
 	t := func(s string) string {
@@ -40,6 +53,9 @@ func GenerateHelp(node *parser.CommandNode, tr Translator) string {
 			}
 		}
 	}
+	if node.Passthrough != nil {
+		fmt.Fprintf(&sb, " [-- %s...]", t(node.Passthrough.Description))
+	}
 	fmt.Fprint(&sb, "\n\n")
 
 	if node.Description != "" {
@@ -67,7 +83,7 @@ func GenerateHelp(node *parser.CommandNode, tr Translator) string {
 		fmt.Fprint(&sb, "\n")
 	}
 
-	if len(node.Flags) > 0 {
+	if len(node.Flags) > 0 || len(builtins) > 0 {
 		fmt.Fprint(&sb, "Flags:\n")
 
 		flagNames := make([]string, 0, len(node.Flags))
@@ -84,24 +100,131 @@ func GenerateHelp(node *parser.CommandNode, tr Translator) string {
 			}
 
 			details := []string{}
-			if meta.Env != "" {
+			if meta.Env != "" && meta.Env != "-" {
 				details = append(details, fmt.Sprintf("env: %s", meta.Env))
 			}
-			if meta.Default != "" {
-				details = append(details, fmt.Sprintf("default: %s", meta.Default))
+			defVal := meta.Default
+			if defVal == "" {
+				defVal = meta.ValueDefault
+			}
+			if defVal != "" && meta.Secret {
+				defVal = resolver.SecretMask
+			}
+			if defVal != "" {
+				details = append(details, fmt.Sprintf("default: %s", defVal))
 			}
 			if meta.Required {
 				details = append(details, "required")
 			}
+			if meta.Secret {
+				details = append(details, fmt.Sprintf("secret: use --%s-file or - for stdin", name))
+			}
+			if from := fromNote(meta); from != "" {
+				details = append(details, from)
+			}
 
 			detailStr := ""
 			if len(details) > 0 {
 				detailStr = fmt.Sprintf(" (%s)", strings.Join(details, ", "))
 			}
 
-			fmt.Fprintf(&sb, "  %s--%-12s %s%s\n", short, name, t(meta.Description), detailStr)
+			label := "--" + name
+			if meta.Negatable {
+				label = "--[no-]" + name
+			}
+			if placeholder := valuePlaceholder(meta); placeholder != "" {
+				label += " " + placeholder
+			}
+
+			fmt.Fprintf(&sb, "  %s%-14s %s%s\n", short, label, t(meta.Description), detailStr)
+		}
+
+		for _, builtin := range builtins {
+			label := "--" + builtin.Name
+			if builtin.Placeholder != "" {
+				label += " " + builtin.Placeholder
+			}
+			fmt.Fprintf(&sb, "  %-14s %s\n", label, t(builtin.Description))
+		}
+	}
+
+	return sb.String()
+}
+
+// fromNote describes where else the value of a flag can be read from.
+func fromNote(meta *parser.FlagMetadata) string {
+	switch {
+	case meta.FromFile && meta.FromStdin:
+		return "@file or - for stdin"
+	case meta.FromFile:
+		return "@file"
+	case meta.FromStdin:
+		return "- for stdin"
+	}
+	return ""
+}
+
+// valuePlaceholder returns the placeholder shown after flags whose value has a
+// specific shape: key=value for maps and the Type() of custom values.
+func valuePlaceholder(meta *parser.FlagMetadata) string {
+	typ := meta.ValueType()
+	if typ.Kind() == reflect.Map && !resolver.IsCustom(typ) {
+		return "key=value"
+	}
+	if v, ok := reflect.New(typ).Interface().(resolver.Value); ok {
+		return v.Type()
+	}
+	return ""
+}
+
+// GenerateFlagReport generates a table of the resolved flags, showing the
+// effective value of each flag and where it came from.
+//
+// Example:
+//
+//	fmt.Print(help.GenerateFlagReport(effectiveFlags))
+func GenerateFlagReport(flags map[string]*parser.FlagMetadata) string {
+	var sb strings.Builder
+
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FLAG\tVALUE\tSOURCE")
+	for _, name := range names {
+		meta := flags[name]
+		value := formatValue(meta.Field)
+		if strings.ContainsAny(value, "\r\n") {
+			value = strconv.Quote(value)
+		}
+		if meta.Secret && meta.Origin.Source != resolver.SourceNone {
+			value = resolver.SecretMask
 		}
+		fmt.Fprintf(w, "--%s\t%s\t%s\n", name, value, meta.Origin)
 	}
+	w.Flush()
 
 	return sb.String()
 }
+
+// formatValue renders the current value of a flag field.
+func formatValue(val reflect.Value) string {
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return "<unset>"
+		}
+		val = val.Elem()
+	}
+	if val.CanAddr() {
+		if v, ok := val.Addr().Interface().(fmt.Stringer); ok {
+			return v.String()
+		}
+	}
+	if val.CanInterface() {
+		return fmt.Sprint(val.Interface())
+	}
+	return ""
+}
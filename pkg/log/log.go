@@ -2,7 +2,12 @@ package log
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // LogLevel represents the severity of a log message.
@@ -23,7 +28,9 @@ type Logger interface {
 	Error(format string, a ...any)
 }
 
-type defaultLogger struct{}
+type defaultLogger struct {
+	out io.Writer
+}
 
 // New creates a new instance of the default Logger.
 //
@@ -32,7 +39,17 @@ type defaultLogger struct{}
 //	logger := log.New()
 //	logger.Info("Starting application...")
 func New() Logger {
-	return &defaultLogger{}
+	return &defaultLogger{out: os.Stdout}
+}
+
+// NewWithWriter creates a new instance of the default Logger writing to w.
+//
+// Example:
+//
+//	var buf bytes.Buffer
+//	logger := log.NewWithWriter(&buf)
+func NewWithWriter(w io.Writer) Logger {
+	return &defaultLogger{out: w}
 }
 
 // Info logs an informational message.
@@ -41,7 +58,7 @@ func New() Logger {
 //
 //	logger.Info("System status: %s", "OK")
 func (l *defaultLogger) Info(format string, a ...any) {
-	logMessage(LogLevelInfo, "", format, a...)
+	logMessage(l.out, LogLevelInfo, "", format, a...)
 }
 
 // Success logs a success message.
@@ -50,7 +67,7 @@ func (l *defaultLogger) Info(format string, a ...any) {
 //
 //	logger.Success("Operation completed successfully")
 func (l *defaultLogger) Success(format string, a ...any) {
-	logMessage(LogLevelSuccess, "", format, a...)
+	logMessage(l.out, LogLevelSuccess, "", format, a...)
 }
 
 // Warning logs a warning message.
@@ -59,7 +76,7 @@ func (l *defaultLogger) Success(format string, a ...any) {
 //
 //	logger.Warning("Disk space is running low")
 func (l *defaultLogger) Warning(format string, a ...any) {
-	logMessage(LogLevelWarning, "", format, a...)
+	logMessage(l.out, LogLevelWarning, "", format, a...)
 }
 
 // Error logs an error message.
@@ -68,11 +85,123 @@ func (l *defaultLogger) Warning(format string, a ...any) {
 //
 //	logger.Error("Failed to connect to database: %v", err)
 func (l *defaultLogger) Error(format string, a ...any) {
-	logMessage(LogLevelError, "", format, a...)
+	logMessage(l.out, LogLevelError, "", format, a...)
+}
+
+// redactedLogger is a Logger masking secrets in the logged messages.
+type redactedLogger struct {
+	logger  Logger
+	mask    string
+	secrets []string // longest first, so that overlapping secrets are fully masked
+}
+
+// Redact wraps a Logger, replacing every occurrence of the given secrets in
+// the logged messages with mask, even inside a word. Only secrets made of
+// digits or of at most two characters must stand on their own, so that a
+// secret such as "42" does not garble "8042". Empty secrets are ignored.
+//
+// Example:
+//
+//	logger := log.Redact(log.New(), "****", token)
+//	logger.Info("token: %s", token) // token: ****
+func Redact(l Logger, mask string, secrets ...string) Logger {
+	var nonEmpty []string
+	for _, secret := range secrets {
+		if secret != "" {
+			nonEmpty = append(nonEmpty, secret)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return l
+	}
+	slices.SortFunc(nonEmpty, func(a, b string) int { return len(b) - len(a) })
+	return &redactedLogger{logger: l, mask: mask, secrets: nonEmpty}
+}
+
+// redact replaces the secrets found in s with the mask.
+func (l *redactedLogger) redact(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		secret := l.match(s, i)
+		if secret == "" {
+			sb.WriteByte(s[i])
+			i++
+			continue
+		}
+		sb.WriteString(l.mask)
+		i += len(secret)
+	}
+	return sb.String()
+}
+
+// match returns the secret starting at s[i], or "". Secrets needing a word
+// boundary only require it next to one of their letters or digits, so "42!"
+// is masked in "42!!" as well.
+func (l *redactedLogger) match(s string, i int) string {
+	for _, secret := range l.secrets {
+		if !strings.HasPrefix(s[i:], secret) {
+			continue
+		}
+		if !needsBoundary(secret) {
+			return secret
+		}
+		first, _ := utf8.DecodeRuneInString(secret)
+		before, _ := utf8.DecodeLastRuneInString(s[:i])
+		if i > 0 && isWordRune(first) && isWordRune(before) {
+			continue
+		}
+		end := i + len(secret)
+		last, _ := utf8.DecodeLastRuneInString(secret)
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if end < len(s) && isWordRune(last) && isWordRune(after) {
+			continue
+		}
+		return secret
+	}
+	return ""
+}
+
+// needsBoundary reports whether a secret is only masked as a whole word,
+// because it is too short or numeric to be told apart from other text.
+func needsBoundary(secret string) bool {
+	if utf8.RuneCountInString(secret) <= 2 {
+		return true
+	}
+	for _, r := range secret {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isWordRune reports whether r is part of a word: a letter or a digit.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Info logs an informational message with secrets redacted.
+func (l *redactedLogger) Info(format string, a ...any) {
+	l.logger.Info("%s", l.redact(fmt.Sprintf(format, a...)))
+}
+
+// Success logs a success message with secrets redacted.
+func (l *redactedLogger) Success(format string, a ...any) {
+	l.logger.Success("%s", l.redact(fmt.Sprintf(format, a...)))
+}
+
+// Warning logs a warning message with secrets redacted.
+func (l *redactedLogger) Warning(format string, a ...any) {
+	l.logger.Warning("%s", l.redact(fmt.Sprintf(format, a...)))
+}
+
+// Error logs an error message with secrets redacted.
+func (l *redactedLogger) Error(format string, a ...any) {
+	l.logger.Error("%s", l.redact(fmt.Sprintf(format, a...)))
 }
 
-// logMessage prints a formatted message to standard output based on log level and debug mode.
-func logMessage(level LogLevel, component string, format string, a ...any) {
+// logMessage prints a formatted message to w based on log level and debug mode.
+func logMessage(w io.Writer, level LogLevel, component string, format string, a ...any) {
 	color := getLogLevelColor(level)
 	coloredLevel := colorize(getLogLevelSymbol(level), color)
 
@@ -81,7 +210,7 @@ func logMessage(level LogLevel, component string, format string, a ...any) {
 		coloredComponent = colorize(component, "blue") + " "
 	}
 
-	fmt.Printf("%s %s%s\n", coloredLevel, coloredComponent, fmt.Sprintf(format, a...))
+	fmt.Fprintf(w, "%s %s%s\n", coloredLevel, coloredComponent, fmt.Sprintf(format, a...))
 }
 
 // getLogLevelColor returns the ANSI color code name associated with a log level.
@@ -2,25 +2,70 @@ package parser
 
 import (
 	"reflect"
+
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
 )
 
 // FlagMetadata holds information about a flag.
 type FlagMetadata struct {
-	Name        string
-	Short       string
-	Description string
-	Default     string
-	Env         string
-	Required    bool
-	Field       reflect.Value
+	Name         string
+	Short        string
+	Description  string
+	Default      string
+	Env          string // Environment variable, "-" disables environment lookup
+	ConfigKey    string // Configuration key, "-" disables configuration lookup
+	Required     bool
+	Negatable    bool   // Accepts --no-<name> to set the flag to false
+	Counter      bool   // Counts occurrences instead of taking a value
+	Separator    string // Splits each value of a multi-value flag
+	Duplicates   string // Policy for repeated keys of map flags: last, first or error
+	Secret       bool   // Hides the value, which can also be read from --<name>-file or stdin
+	FromFile     bool   // Reads @path values from the file
+	FromStdin    bool   // Reads a "-" value from stdin
+	Field        reflect.Value
+	Origin       resolver.Origin // Where the bound value came from, set after binding
+	ValueDefault string          // String() of a Value field when the struct was parsed
+
+	initial reflect.Value // Copy of the field when the struct was parsed
+}
+
+// TakesValue reports whether the flag expects a value on the command line.
+// Boolean and counter flags are switches and take no value.
+func (m *FlagMetadata) TakesValue() bool {
+	return !m.Counter && m.ValueType().Kind() != reflect.Bool
+}
+
+// ValueType returns the type of the bound value, looking through a pointer field.
+func (m *FlagMetadata) ValueType() reflect.Type {
+	return valueType(m.Field.Type())
+}
+
+// Changed reports whether the flag was explicitly passed on the command line.
+func (m *FlagMetadata) Changed() bool {
+	return m.Origin.Source == resolver.SourceFlag
+}
+
+// Reset restores the field to the value it had when the struct was parsed,
+// dropping the value bound by a previous run.
+func (m *FlagMetadata) Reset() {
+	reset(m.Field, m.initial)
 }
 
 // ArgMetadata holds information about a positional argument.
 type ArgMetadata struct {
+	Name        string
 	Description string
 	Required    bool
 	IsGreedy    bool
 	Field       reflect.Value
+
+	initial reflect.Value // Copy of the field when the struct was parsed
+}
+
+// Reset restores the field to the value it had when the struct was parsed,
+// dropping the value bound by a previous run.
+func (m *ArgMetadata) Reset() {
+	reset(m.Field, m.initial)
 }
 
 // CommandNode represents a node in the command tree.
@@ -31,6 +76,7 @@ type CommandNode struct {
 	Flags       map[string]*FlagMetadata
 	ShortFlags  map[string]string // Maps short name to full name
 	Args        []*ArgMetadata
+	Passthrough *ArgMetadata // Receives arguments following "--"
 	Children    map[string]*CommandNode
 	Value       reflect.Value
 	Type        reflect.Type
@@ -52,3 +98,72 @@ func NewCommandNode(name, description string, val reflect.Value) *CommandNode {
 		Type:        val.Type(),
 	}
 }
+
+// FlagOrigin returns where the value of the named flag came from in the last run.
+// Only the flags declared on this node are considered.
+//
+// Example:
+//
+//	if origin, ok := node.FlagOrigin("port"); ok && origin.Source == resolver.SourceEnv {
+//		fmt.Println("port read from", origin.Name)
+//	}
+func (n *CommandNode) FlagOrigin(name string) (resolver.Origin, bool) {
+	meta, ok := n.Flags[name]
+	if !ok {
+		return resolver.Origin{}, false
+	}
+	return meta.Origin, true
+}
+
+// Changed reports whether the named flag was explicitly passed on the command line.
+//
+// Example:
+//
+//	if node.Changed("port") { ... }
+func (n *CommandNode) Changed(name string) bool {
+	meta, ok := n.Flags[name]
+	return ok && meta.Changed()
+}
+
+// snapshot returns a copy of a settable field, or an invalid value when the
+// field can't be set.
+func snapshot(field reflect.Value) reflect.Value {
+	if !field.CanSet() {
+		return reflect.Value{}
+	}
+	c := reflect.New(field.Type()).Elem()
+	c.Set(cloneValue(field))
+	return c
+}
+
+// reset sets field to a copy of initial, unless no snapshot was taken.
+func reset(field, initial reflect.Value) {
+	if initial.IsValid() && field.CanSet() {
+		field.Set(cloneValue(initial))
+	}
+}
+
+// cloneValue copies the entries of a map or slice, so that binding into the
+// field never changes the snapshot. Other values are returned as is.
+func cloneValue(val reflect.Value) reflect.Value {
+	switch val.Kind() {
+	case reflect.Map:
+		if val.IsNil() {
+			return val
+		}
+		m := reflect.MakeMapWithSize(val.Type(), val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(iter.Key(), iter.Value())
+		}
+		return m
+	case reflect.Slice:
+		if val.IsNil() {
+			return val
+		}
+		s := reflect.MakeSlice(val.Type(), val.Len(), val.Len())
+		reflect.Copy(s, val)
+		return s
+	}
+	return val
+}
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/mirkobrombin/go-cli-builder/v2/pkg/resolver"
 	"github.com/mirkobrombin/go-foundation/pkg/tags"
 )
 
@@ -168,13 +169,30 @@ func ParseStruct(node *CommandNode, val reflect.Value) error {
 			}
 
 			flagMeta := &FlagMetadata{
-				Name:        name,
-				Short:       short,
-				Description: field.Tag.Get("help"),
-				Default:     field.Tag.Get("default"),
-				Env:         field.Tag.Get("env"),
-				Required:    required,
-				Field:       fieldVal,
+				Name:         name,
+				Short:        short,
+				Description:  field.Tag.Get("help"),
+				Default:      field.Tag.Get("default"),
+				Env:          field.Tag.Get("env"),
+				ConfigKey:    field.Tag.Get("config"),
+				Required:     required,
+				Negatable:    isNegatable(field),
+				Separator:    field.Tag.Get("sep"),
+				Field:        fieldVal,
+				ValueDefault: resolver.ValueString(fieldVal),
+				initial:      snapshot(fieldVal),
+			}
+			if err := applyFlagType(flagMeta, field); err != nil {
+				return err
+			}
+			if err := applyDuplicates(flagMeta, field); err != nil {
+				return err
+			}
+			if err := applySecret(flagMeta, field); err != nil {
+				return err
+			}
+			if err := applyFrom(flagMeta, field); err != nil {
+				return err
 			}
 
 			node.Flags[name] = flagMeta
@@ -186,6 +204,22 @@ func ParseStruct(node *CommandNode, val reflect.Value) error {
 
 		if flagTag, ok := field.Tag.Lookup("flag"); ok {
 			meta := parseFlagTag(flagTag, fieldVal)
+			meta.Negatable = isNegatable(field)
+			meta.Separator = field.Tag.Get("sep")
+			meta.Env = field.Tag.Get("env")
+			meta.ConfigKey = field.Tag.Get("config")
+			if err := applyFlagType(meta, field); err != nil {
+				return err
+			}
+			if err := applyDuplicates(meta, field); err != nil {
+				return err
+			}
+			if err := applySecret(meta, field); err != nil {
+				return err
+			}
+			if err := applyFrom(meta, field); err != nil {
+				return err
+			}
 
 			name := meta.Name
 			if name == "" {
@@ -199,19 +233,36 @@ func ParseStruct(node *CommandNode, val reflect.Value) error {
 			continue
 		}
 
+		if _, ok := field.Tag.Lookup("passthrough"); ok {
+			if field.Type.Kind() != reflect.Slice || field.Type.Elem().Kind() != reflect.String {
+				return fmt.Errorf("passthrough field %s must be a []string", field.Name)
+			}
+
+			node.Passthrough = &ArgMetadata{
+				Name:        strings.ToLower(field.Name),
+				Description: field.Tag.Get("help"),
+				IsGreedy:    true,
+				Field:       fieldVal,
+				initial:     snapshot(fieldVal),
+			}
+			continue
+		}
+
 		if _, ok := field.Tag.Lookup("arg"); ok {
 			required := false
 			if reqTag, ok := field.Tag.Lookup("required"); ok && reqTag == "true" {
 				required = true
 			}
 
-			isGreedy := field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.String
+			isGreedy := field.Type.Kind() == reflect.Slice && !resolver.IsCustom(field.Type)
 
 			argMeta := &ArgMetadata{
+				Name:        strings.ToLower(field.Name),
 				Description: field.Tag.Get("help"),
 				Required:    required,
 				IsGreedy:    isGreedy,
 				Field:       fieldVal,
+				initial:     snapshot(fieldVal),
 			}
 
 			node.Args = append(node.Args, argMeta)
@@ -223,7 +274,11 @@ func ParseStruct(node *CommandNode, val reflect.Value) error {
 
 // parseFlagTag parses the flag:"short:x, long:y, name:z" format.
 func parseFlagTag(tag string, fieldVal reflect.Value) *FlagMetadata {
-	meta := &FlagMetadata{Field: fieldVal}
+	meta := &FlagMetadata{
+		Field:        fieldVal,
+		ValueDefault: resolver.ValueString(fieldVal),
+		initial:      snapshot(fieldVal),
+	}
 
 	parsed := flagTagParser.Parse(tag)
 
@@ -241,3 +296,96 @@ func parseFlagTag(tag string, fieldVal reflect.Value) *FlagMetadata {
 
 	return meta
 }
+
+// isNegatable reports whether a boolean field gets an automatic --no-<name> form.
+// It can be disabled with the negatable:"false" tag.
+func isNegatable(field reflect.StructField) bool {
+	if valueType(field.Type).Kind() != reflect.Bool {
+		return false
+	}
+	return field.Tag.Get("negatable") != "false"
+}
+
+// applyFlagType applies the type tag to the flag metadata.
+// Currently only type:"count" is supported, which requires an integer field.
+func applyFlagType(meta *FlagMetadata, field reflect.StructField) error {
+	typeTag, ok := field.Tag.Lookup("type")
+	if !ok {
+		return nil
+	}
+
+	switch typeTag {
+	case "count":
+		switch valueType(field.Type).Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			meta.Counter = true
+		default:
+			return fmt.Errorf("counter flag %s must be an integer", field.Name)
+		}
+	default:
+		return fmt.Errorf("unknown flag type %q on field %s", typeTag, field.Name)
+	}
+	return nil
+}
+
+// applyDuplicates applies the duplicates tag, which decides how map flags
+// handle a key given more than once. The last value wins by default.
+func applyDuplicates(meta *FlagMetadata, field reflect.StructField) error {
+	policy, ok := field.Tag.Lookup("duplicates")
+	if !ok {
+		meta.Duplicates = "last"
+		return nil
+	}
+
+	switch policy {
+	case "last", "first", "error":
+		meta.Duplicates = policy
+	default:
+		return fmt.Errorf("unknown duplicates policy %q on field %s", policy, field.Name)
+	}
+	return nil
+}
+
+// applySecret applies the secret tag, which hides the value of a flag. Secret
+// flags must take a value.
+func applySecret(meta *FlagMetadata, field reflect.StructField) error {
+	if field.Tag.Get("secret") != "true" {
+		return nil
+	}
+	if !meta.TakesValue() {
+		return fmt.Errorf("secret flag on field %s must take a value", field.Name)
+	}
+	meta.Secret = true
+	return nil
+}
+
+// applyFrom applies the from tag, listing where else the value of a flag can be
+// read: "file" for @path values and "stdin" for a "-" value.
+func applyFrom(meta *FlagMetadata, field reflect.StructField) error {
+	from, ok := field.Tag.Lookup("from")
+	if !ok {
+		return nil
+	}
+	if !meta.TakesValue() {
+		return fmt.Errorf("from tag on field %s requires a flag taking a value", field.Name)
+	}
+	for _, src := range strings.Split(from, ",") {
+		switch strings.TrimSpace(src) {
+		case "file":
+			meta.FromFile = true
+		case "stdin":
+			meta.FromStdin = true
+		default:
+			return fmt.Errorf("unknown from source %q on field %s", src, field.Name)
+		}
+	}
+	return nil
+}
+
+// valueType returns t, or its element type when t is a pointer.
+func valueType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
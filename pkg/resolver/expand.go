@@ -0,0 +1,122 @@
+package resolver
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Expand replaces $NAME, ${NAME} and ${NAME:-fallback} references in s using
+// lookup, and \$ with a literal $. The fallback is used when lookup finds no
+// value or an empty one, and may itself contain references, as in
+// ${XDG_CACHE_HOME:-${HOME}/.cache}. Lookup receives the raw name between
+// braces, so it can implement namespaces such as ${flag:name}.
+//
+// Example:
+//
+//	val, err := resolver.Expand("${XDG_CACHE_HOME:-/tmp}/tool", func(name string) (string, bool, error) {
+//		val, ok := os.LookupEnv(name)
+//		return val, ok, nil
+//	})
+func Expand(s string, lookup func(name string) (string, bool, error)) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) && s[i+1] == '$' {
+			sb.WriteByte('$')
+			i++
+			continue
+		}
+		if c != '$' || i+1 == len(s) {
+			sb.WriteByte(c)
+			continue
+		}
+
+		if s[i+1] == '{' {
+			end := closingBrace(s[i:])
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable reference in %q", s)
+			}
+			name, fallback, hasFallback := strings.Cut(s[i+2:i+end], ":-")
+			if name == "" {
+				return "", fmt.Errorf("empty variable reference in %q", s)
+			}
+			val, ok, err := lookup(name)
+			if err != nil {
+				return "", err
+			}
+			if (!ok || val == "") && hasFallback {
+				if val, err = Expand(fallback, lookup); err != nil {
+					return "", err
+				}
+			}
+			sb.WriteString(val)
+			i += end
+			continue
+		}
+
+		j := i + 1
+		for j < len(s) && (s[j] == '_' || s[j] >= 'A' && s[j] <= 'Z' || s[j] >= 'a' && s[j] <= 'z' || j > i+1 && s[j] >= '0' && s[j] <= '9') {
+			j++
+		}
+		if j == i+1 {
+			sb.WriteByte(c)
+			continue
+		}
+		val, _, err := lookup(s[i+1 : j])
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(val)
+		i = j - 1
+	}
+	return sb.String(), nil
+}
+
+// closingBrace returns the index of the brace closing the reference at the
+// start of s, skipping nested ${...} references, or -1.
+func closingBrace(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == '$':
+			i++
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// ExpandHome replaces a leading ~ in s with the home directory of the user:
+// the HOME variable found by lookup, like $HOME, or os.UserHomeDir when lookup
+// is nil or finds no value.
+//
+// Example:
+//
+//	path, err := resolver.ExpandHome("~/.cache/tool", nil)
+func ExpandHome(s string, lookup func(name string) (string, bool, error)) (string, error) {
+	if s != "~" && !strings.HasPrefix(s, "~/") {
+		return s, nil
+	}
+	if lookup != nil {
+		home, ok, err := lookup("HOME")
+		if err != nil {
+			return "", err
+		}
+		if ok && home != "" {
+			return home + s[1:], nil
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot expand ~: %w", err)
+	}
+	return home + s[1:], nil
+}
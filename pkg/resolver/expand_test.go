@@ -0,0 +1,88 @@
+package resolver
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	vars := map[string]string{"HOME": "/home/me", "EMPTY": "", "NAME": "tool"}
+	lookup := func(name string) (string, bool, error) {
+		if name == "FAIL" {
+			return "", false, errors.New("lookup failed")
+		}
+		val, ok := vars[name]
+		return val, ok, nil
+	}
+
+	tests := []struct {
+		in   string
+		want string
+		err  string
+	}{
+		{in: "plain", want: "plain"},
+		{in: "$HOME/.cache", want: "/home/me/.cache"},
+		{in: "${HOME}/.cache", want: "/home/me/.cache"},
+		{in: "${NAME}s", want: "tools"},
+		{in: "$UNDEFINED/x", want: "/x"},
+		{in: "${UNDEFINED:-/tmp}/x", want: "/tmp/x"},
+		{in: "${EMPTY:-/tmp}", want: "/tmp"},
+		{in: "${HOME:-/tmp}", want: "/home/me"},
+		{in: "${XDG_CACHE_HOME:-$HOME/.cache}/tool", want: "/home/me/.cache/tool"},
+		{in: "${XDG_CACHE_HOME:-${HOME}/.cache}/tool", want: "/home/me/.cache/tool"},
+		{in: "${A:-${B:-${NAME}}}", want: "tool"},
+		{in: "${A:-x}}", want: "x}"},
+		{in: `\$HOME`, want: "$HOME"},
+		{in: `${A:-\${B}}`, want: "${B}"},
+		{in: "cost: 5$", want: "cost: 5$"},
+		{in: "$1", want: "$1"},
+		{in: "${HOME", err: `unterminated variable reference in "${HOME"`},
+		{in: "${A:-${HOME}", err: `unterminated variable reference in "${A:-${HOME}"`},
+		{in: "${}", err: `empty variable reference in "${}"`},
+		{in: "${A:-${FAIL}}", err: "lookup failed"},
+	}
+
+	for _, tt := range tests {
+		got, err := Expand(tt.in, lookup)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expand(%q) error = %v, want %q", tt.in, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Expand(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	t.Setenv("HOME", "/home/os")
+	lookup := func(name string) (string, bool, error) {
+		if name == "HOME" {
+			return "/home/me", true, nil
+		}
+		return "", false, nil
+	}
+	missing := func(name string) (string, bool, error) { return "", false, nil }
+
+	tests := []struct {
+		in     string
+		lookup func(name string) (string, bool, error)
+		want   string
+	}{
+		{in: "~/x", lookup: lookup, want: "/home/me/x"},
+		{in: "~", lookup: lookup, want: "/home/me"},
+		{in: "~/x", lookup: missing, want: "/home/os/x"},
+		{in: "~/x", want: "/home/os/x"},
+		{in: "~user/x", lookup: lookup, want: "~user/x"},
+		{in: "a/~/x", lookup: lookup, want: "a/~/x"},
+	}
+
+	for _, tt := range tests {
+		got, err := ExpandHome(tt.in, tt.lookup)
+		if err != nil || got != tt.want {
+			t.Errorf("ExpandHome(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package resolver
+
+import "os"
+
+// Provider supplies flag values not given on the command line, e.g. from the
+// environment, configuration files or secret stores. Lookup reports whether it
+// has a value for the query, and where the value came from.
+type Provider interface {
+	Lookup(q Query) ([]string, Origin, bool, error)
+}
+
+// ProviderFunc adapts a function to the Provider interface.
+type ProviderFunc func(q Query) ([]string, Origin, bool, error)
+
+// Lookup calls f(q).
+func (f ProviderFunc) Lookup(q Query) ([]string, Origin, bool, error) {
+	return f(q)
+}
+
+// EnvLookup finds environment variables.
+type EnvLookup interface {
+	LookupEnv(name string) (string, Origin, bool)
+}
+
+// osEnv looks variables up in the process environment.
+type osEnv struct{}
+
+// LookupEnv implements EnvLookup using os.LookupEnv.
+func (osEnv) LookupEnv(name string) (string, Origin, bool) {
+	val, ok := os.LookupEnv(name)
+	return val, Origin{Source: SourceEnv, Name: name}, ok
+}
+
+// EnvProvider returns a Provider reading the environment variable of the query
+// from env, or from the process environment when env is nil.
+//
+// Example:
+//
+//	p := resolver.EnvProvider(nil)
+func EnvProvider(env EnvLookup) Provider {
+	if env == nil {
+		env = osEnv{}
+	}
+	return ProviderFunc(func(q Query) ([]string, Origin, bool, error) {
+		if q.Env == "" || q.Env == "-" {
+			return nil, Origin{}, false, nil
+		}
+		val, origin, ok := env.LookupEnv(q.Env)
+		if !ok {
+			return nil, Origin{}, false, nil
+		}
+		return []string{val}, origin, true, nil
+	})
+}
+
+// ConfigLookup finds the values stored under a configuration key.
+type ConfigLookup interface {
+	Lookup(key string) ([]string, Origin, bool)
+}
+
+// ConfigProvider returns a Provider reading the configuration key of the query from cfg.
+//
+// Example:
+//
+//	p := resolver.ConfigProvider(cfg)
+func ConfigProvider(cfg ConfigLookup) Provider {
+	return ProviderFunc(func(q Query) ([]string, Origin, bool, error) {
+		if q.ConfigKey == "" || q.ConfigKey == "-" {
+			return nil, Origin{}, false, nil
+		}
+		vals, origin, ok := cfg.Lookup(q.ConfigKey)
+		return vals, origin, ok, nil
+	})
+}
+
+// MapSource is a Provider backed by a map, handy to inject values in tests.
+// Values are looked up by environment variable name, then configuration key,
+// then flag name. It is also an EnvLookup, replacing the process environment.
+//
+// Example:
+//
+//	src := resolver.MapSource{"API_TOKEN": "test", "deploy.region": "eu"}
+type MapSource map[string]string
+
+// Lookup implements Provider.
+func (m MapSource) Lookup(q Query) ([]string, Origin, bool, error) {
+	for _, key := range []string{q.Env, q.ConfigKey, q.Flag} {
+		if key == "" || key == "-" {
+			continue
+		}
+		if val, ok := m[key]; ok {
+			return []string{val}, Origin{Source: SourceExternal, Name: key}, true, nil
+		}
+	}
+	return nil, Origin{}, false, nil
+}
+
+// LookupEnv implements EnvLookup.
+func (m MapSource) LookupEnv(name string) (string, Origin, bool) {
+	val, ok := m[name]
+	return val, Origin{Source: SourceEnv, Name: name}, ok
+}
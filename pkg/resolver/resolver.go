@@ -1,20 +1,103 @@
 package resolver
 
 import (
+	"encoding"
+	"errors"
 	"fmt"
-	"os"
+	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// Value is the interface implemented by custom flag and argument types.
+// Set is called with the raw string of every occurrence, String renders the
+// current value (used as the default in help output) and Type names the value.
+type Value interface {
+	Set(string) error
+	String() string
+	Type() string
+}
+
+var (
+	valueType           = reflect.TypeFor[Value]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	urlType             = reflect.TypeFor[url.URL]()
+)
+
+// IsCustom reports whether values of type t are bound through a custom parser,
+// i.e. *t implements Value or encoding.TextUnmarshaler, or t is url.URL.
+// Such types are bound as a whole even if their kind is a slice or a struct.
+//
+// Example:
+//
+//	resolver.IsCustom(reflect.TypeFor[net.IP]()) // true
+func IsCustom(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return pt.Implements(valueType) || pt.Implements(textUnmarshalerType) || t == urlType
+}
+
+// ValueString returns the String() of a field implementing Value, or an empty
+// string for any other field.
+//
+// Example:
+//
+//	def := resolver.ValueString(meta.Field)
+func ValueString(val reflect.Value) string {
+	if val.Kind() == reflect.Ptr && !val.IsNil() {
+		val = val.Elem()
+	}
+	if !val.CanAddr() {
+		return ""
+	}
+	if v, ok := val.Addr().Interface().(Value); ok {
+		return v.String()
+	}
+	return ""
+}
+
+// bindCustom binds value through Value, encoding.TextUnmarshaler or url.Parse.
+// It reports false when the type has no custom parser.
+func bindCustom(val reflect.Value, value string) (bool, error) {
+	if !val.CanAddr() {
+		return false, nil
+	}
+
+	switch v := val.Addr().Interface().(type) {
+	case Value:
+		if err := v.Set(value); err != nil {
+			return true, fmt.Errorf("invalid %s: %w", v.Type(), err)
+		}
+		return true, nil
+	case encoding.TextUnmarshaler:
+		if err := v.UnmarshalText([]byte(value)); err != nil {
+			return true, fmt.Errorf("invalid %s: %w", val.Type(), err)
+		}
+		return true, nil
+	case *url.URL:
+		u, err := url.Parse(value)
+		if err != nil {
+			return true, fmt.Errorf("invalid URL: %v", value)
+		}
+		*v = *u
+		return true, nil
+	}
+	return false, nil
+}
+
 // BindValue binds a string value to a reflect.Value.
+// Types implementing Value or encoding.TextUnmarshaler are bound through them.
 //
 // Example:
 //
 //	var x int
 //	err := resolver.BindValue(reflect.ValueOf(&x).Elem(), "42")
 func BindValue(val reflect.Value, value string) error {
+	if ok, err := bindCustom(val, value); ok {
+		return err
+	}
+
 	switch val.Kind() {
 	case reflect.String:
 		val.SetString(value)
@@ -26,36 +109,195 @@ func BindValue(val reflect.Value, value string) error {
 			}
 			val.SetInt(int64(d))
 		} else {
-			i, err := strconv.ParseInt(value, 10, 64)
+			i, err := ParseInt(value, val.Type().Bits())
 			if err != nil {
-				return fmt.Errorf("invalid integer: %v", value)
+				return err
 			}
 			val.SetInt(i)
 		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := ParseUint(value, val.Type().Bits())
+		if err != nil {
+			return err
+		}
+		val.SetUint(u)
 	case reflect.Float32, reflect.Float64:
-		f, err := strconv.ParseFloat(value, 64)
+		f, err := ParseFloat(value, val.Type().Bits())
 		if err != nil {
-			return fmt.Errorf("invalid float: %v", value)
+			return err
 		}
 		val.SetFloat(f)
+	case reflect.Complex64, reflect.Complex128:
+		c, err := ParseComplex(value, val.Type().Bits())
+		if err != nil {
+			return err
+		}
+		val.SetComplex(c)
 	case reflect.Bool:
 		b, err := ParseBool(value)
 		if err != nil {
 			return fmt.Errorf("invalid boolean: %v", value)
 		}
 		val.SetBool(b)
+	case reflect.Ptr:
+		elem := reflect.New(val.Type().Elem())
+		if err := BindValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		val.Set(elem)
+	case reflect.Map:
+		key, elem, err := ParseMapEntry(val.Type(), value)
+		if err != nil {
+			return err
+		}
+		if val.IsNil() {
+			val.Set(reflect.MakeMap(val.Type()))
+		}
+		val.SetMapIndex(key, elem)
 	case reflect.Slice:
-		if val.Type().Elem().Kind() == reflect.String {
-			val.Set(reflect.Append(val, reflect.ValueOf(value)))
-		} else {
-			return fmt.Errorf("unsupported slice type: %v", val.Type().Elem().Kind())
+		elem := reflect.New(val.Type().Elem()).Elem()
+		if err := BindValue(elem, value); err != nil {
+			return err
 		}
+		val.Set(reflect.Append(val, elem))
 	default:
 		return fmt.Errorf("unsupported type: %v", val.Kind())
 	}
 	return nil
 }
 
+// BindSlice replaces the content of a slice with the given values, binding
+// each of them to the element type. Errors name the offending index.
+//
+// Example:
+//
+//	var ports []int
+//	err := resolver.BindSlice(reflect.ValueOf(&ports).Elem(), []string{"80", "443"})
+func BindSlice(val reflect.Value, values []string) error {
+	s := reflect.MakeSlice(val.Type(), 0, len(values))
+	for i, v := range values {
+		elem := reflect.New(val.Type().Elem()).Elem()
+		if err := BindValue(elem, v); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+		s = reflect.Append(s, elem)
+	}
+	val.Set(s)
+	return nil
+}
+
+// ParseMapEntry parses a key=value pair into a key and an element of the given map type.
+//
+// Example:
+//
+//	k, v, err := resolver.ParseMapEntry(reflect.TypeFor[map[string]int](), "retries=3")
+func ParseMapEntry(mapType reflect.Type, entry string) (reflect.Value, reflect.Value, error) {
+	rawKey, rawVal, ok := strings.Cut(entry, "=")
+	if !ok {
+		return reflect.Value{}, reflect.Value{}, fmt.Errorf("expected key=value: %v", entry)
+	}
+
+	key := reflect.New(mapType.Key()).Elem()
+	if err := BindValue(key, rawKey); err != nil {
+		return reflect.Value{}, reflect.Value{}, fmt.Errorf("key %q: %w", rawKey, err)
+	}
+
+	elem := reflect.New(mapType.Elem()).Elem()
+	if err := BindValue(elem, rawVal); err != nil {
+		return reflect.Value{}, reflect.Value{}, fmt.Errorf("key %q: %w", rawKey, err)
+	}
+
+	return key, elem, nil
+}
+
+// ParseInt parses a signed integer that must fit in the given bit size.
+// Numbers are decimal, so a leading 0 is not octal ("08" is 8), unless they
+// start with a 0x, 0o or 0b prefix. Underscores are accepted between digits.
+//
+// Example:
+//
+//	i, err := resolver.ParseInt("0x_ff", 16)
+func ParseInt(str string, bits int) (int64, error) {
+	digits, base := intLiteral(str)
+	i, err := strconv.ParseInt(digits, base, bits)
+	if err != nil {
+		return 0, numError("integer", str, bits, err)
+	}
+	return i, nil
+}
+
+// ParseUint parses an unsigned integer that must fit in the given bit size.
+// It accepts the same literal forms as ParseInt.
+//
+// Example:
+//
+//	u, err := resolver.ParseUint("0o755", 32)
+func ParseUint(str string, bits int) (uint64, error) {
+	digits, base := intLiteral(str)
+	u, err := strconv.ParseUint(digits, base, bits)
+	if err != nil {
+		return 0, numError("unsigned integer", str, bits, err)
+	}
+	return u, nil
+}
+
+// intLiteral returns the string and base to pass to strconv for an integer
+// literal. Prefixed literals are left to strconv (base 0), while the
+// underscores of decimal ones are removed once they are known to sit between
+// digits, since strconv only accepts them with base 0.
+func intLiteral(str string) (string, int) {
+	s := str
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	if len(s) > 1 && s[0] == '0' && strings.ContainsRune("xXoObB", rune(s[1])) {
+		return str, 0
+	}
+	if !strings.Contains(s, "_") {
+		return str, 10
+	}
+	if strings.HasPrefix(s, "_") || strings.HasSuffix(s, "_") || strings.Contains(s, "__") {
+		return str, 10 // rejected by strconv
+	}
+	return strings.ReplaceAll(str, "_", ""), 10
+}
+
+// ParseFloat parses a floating point number that must fit in the given bit size.
+// It accepts Go float literals, including hex floats and underscores.
+//
+// Example:
+//
+//	f, err := resolver.ParseFloat("1_000.5", 64)
+func ParseFloat(str string, bits int) (float64, error) {
+	f, err := strconv.ParseFloat(str, bits)
+	if err != nil {
+		return 0, numError("float", str, bits, err)
+	}
+	return f, nil
+}
+
+// ParseComplex parses a complex number that must fit in the given bit size.
+//
+// Example:
+//
+//	c, err := resolver.ParseComplex("1+2i", 128)
+func ParseComplex(str string, bits int) (complex128, error) {
+	c, err := strconv.ParseComplex(str, bits)
+	if err != nil {
+		return 0, numError("complex", str, bits, err)
+	}
+	return c, nil
+}
+
+// numError turns a strconv error into a readable message, telling range
+// errors apart from syntax errors.
+func numError(kind, str string, bits int, err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("%s out of range for %d bits: %v", kind, bits, str)
+	}
+	return fmt.Errorf("invalid %s: %v", kind, str)
+}
+
 // ParseBool parses a boolean string with support for more formats.
 //
 // Example:
@@ -72,24 +314,165 @@ func ParseBool(str string) (bool, error) {
 }
 
 // GetValue returns the value to bind based on priority: CLI > Env > Default.
+// An explicit CLI value always wins, so a negated boolean (--no-<name>, passed
+// as "false") overrides any environment variable or default.
+//
+// Deprecated: use Resolve, which also queries configuration files and custom
+// sources, and reports where the value came from.
 //
 // Example:
 //
 //	val, err := resolver.GetValue(nil, "ENV_VAR", "default", false)
 func GetValue(cliVal *string, envName, defVal string, isFlag bool) (string, error) {
+	q := Query{Env: envName, Default: defVal}
 	if cliVal != nil {
-		return *cliVal, nil
+		q.CLI = []string{*cliVal}
+	}
+	vals, _, err := Resolve(q, EnvProvider(nil))
+	if err != nil || len(vals) == 0 {
+		return "", err
 	}
+	return vals[len(vals)-1], nil
+}
+
+// SecretMask replaces the value of secret flags wherever it is rendered.
+const SecretMask = "********"
 
-	if envName != "" {
-		if val, ok := os.LookupEnv(envName); ok {
-			return val, nil
+// SourceKind identifies the kind of source a resolved value came from.
+type SourceKind int
+
+const (
+	SourceNone SourceKind = iota
+	SourceFlag
+	SourceEnv
+	SourceConfig
+	SourceExternal
+	SourceDefault
+)
+
+// String returns the name of the source kind.
+func (s SourceKind) String() string {
+	switch s {
+	case SourceFlag:
+		return "flag"
+	case SourceEnv:
+		return "env"
+	case SourceConfig:
+		return "config"
+	case SourceExternal:
+		return "external"
+	case SourceDefault:
+		return "default"
+	default:
+		return "none"
+	}
+}
+
+// Origin describes where a resolved value came from. Name holds the flag or
+// environment variable the value was read from, File, Key and Line locate
+// values read from a configuration, dotenv or flag value file.
+type Origin struct {
+	Source SourceKind
+	Name   string
+	File   string
+	Key    string
+	Line   int
+}
+
+// String returns a human readable description of the origin.
+func (o Origin) String() string {
+	switch o.Source {
+	case SourceFlag:
+		if o.File != "" {
+			return fmt.Sprintf("%s %s (%s)", o.Source, o.Name, o.File)
 		}
+		return fmt.Sprintf("%s %s", o.Source, o.Name)
+	case SourceEnv:
+		if o.File != "" {
+			return fmt.Sprintf("%s %s (%s:%d)", o.Source, o.Name, o.File, o.Line)
+		}
+		return fmt.Sprintf("%s %s", o.Source, o.Name)
+	case SourceConfig:
+		return fmt.Sprintf("%s %s:%d (%s)", o.Source, o.File, o.Line, o.Key)
+	case SourceExternal:
+		return fmt.Sprintf("%s %s", o.Source, o.Name)
+	default:
+		return o.Source.String()
 	}
+}
 
-	if defVal != "" {
-		return defVal, nil
+// Query describes the sources of a flag value.
+type Query struct {
+	Flag      string   // Flag name
+	CLI       []string // Values given on the command line, one per occurrence
+	Env       string   // Environment variable name, "-" disables environment lookup
+	ConfigKey string   // Configuration key, "-" disables configuration lookup
+	Default   string   // Default value
+	Sep       string   // Separator of list values read from providers and default
+}
+
+// Resolve returns the values to bind based on priority: CLI > providers, in
+// order > Default, along with their origin. When a provider returns a single
+// value, it is split on q.Sep like the default value. An explicit CLI value
+// always wins, so a negated boolean (--no-<name>, passed as "false") overrides
+// any provider or default.
+//
+// Example:
+//
+//	vals, origin, err := resolver.Resolve(q, resolver.EnvProvider(nil), resolver.ConfigProvider(cfg))
+func Resolve(q Query, providers ...Provider) ([]string, Origin, error) {
+	if len(q.CLI) > 0 {
+		origin := Origin{Source: SourceFlag}
+		if q.Flag != "" {
+			origin.Name = "--" + q.Flag
+		}
+		return q.CLI, origin, nil
 	}
 
-	return "", nil
+	for _, p := range providers {
+		vals, origin, ok, err := p.Lookup(q)
+		if err != nil {
+			return nil, Origin{}, err
+		}
+		if ok {
+			if len(vals) == 1 {
+				vals = splitValue(vals[0], q.Sep)
+			}
+			return vals, origin, nil
+		}
+	}
+
+	if q.Default != "" {
+		return splitValue(q.Default, q.Sep), Origin{Source: SourceDefault}, nil
+	}
+
+	return nil, Origin{}, nil
+}
+
+// SplitValues splits every value on sep, flattening the result.
+// Values are returned unchanged when sep is empty.
+//
+// Example:
+//
+//	vals := resolver.SplitValues([]string{"a,b", "c"}, ",") // [a b c]
+func SplitValues(values []string, sep string) []string {
+	if sep == "" || len(values) == 0 {
+		return values
+	}
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		out = append(out, strings.Split(v, sep)...)
+	}
+	return out
+}
+
+// splitValue splits a single list value, treating an empty string as an empty list.
+func splitValue(val, sep string) []string {
+	if sep == "" {
+		return []string{val}
+	}
+	if val == "" {
+		return nil
+	}
+	return strings.Split(val, sep)
 }
@@ -0,0 +1,194 @@
+package resolver
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseInt(t *testing.T) {
+	tests := []struct {
+		in   string
+		bits int
+		want int64
+		err  string
+	}{
+		{in: "42", bits: 64, want: 42},
+		{in: "-42", bits: 64, want: -42},
+		{in: "+7", bits: 8, want: 7},
+		{in: "08", bits: 64, want: 8},
+		{in: "010", bits: 64, want: 10},
+		{in: "1_000", bits: 64, want: 1000},
+		{in: "0x_ff", bits: 16, want: 255},
+		{in: "0XFF", bits: 16, want: 255},
+		{in: "0o755", bits: 16, want: 0o755},
+		{in: "0b101", bits: 8, want: 5},
+		{in: "-0x80", bits: 8, want: -128},
+		{in: "127", bits: 8, want: 127},
+		{in: "-128", bits: 8, want: -128},
+		{in: "128", bits: 8, err: "integer out of range for 8 bits: 128"},
+		{in: "-129", bits: 8, err: "integer out of range for 8 bits: -129"},
+		{in: "32768", bits: 16, err: "integer out of range for 16 bits: 32768"},
+		{in: "2147483647", bits: 32, want: 2147483647},
+		{in: "2147483648", bits: 32, err: "integer out of range for 32 bits: 2147483648"},
+		{in: "9223372036854775808", bits: 64, err: "integer out of range for 64 bits: 9223372036854775808"},
+		{in: "0x80", bits: 8, err: "integer out of range for 8 bits: 0x80"},
+		{in: "_1", bits: 64, err: "invalid integer: _1"},
+		{in: "1__0", bits: 64, err: "invalid integer: 1__0"},
+		{in: "1_", bits: 64, err: "invalid integer: 1_"},
+		{in: "-_1", bits: 64, err: "invalid integer: -_1"},
+		{in: "0x", bits: 64, err: "invalid integer: 0x"},
+		{in: "0b2", bits: 64, err: "invalid integer: 0b2"},
+		{in: "1.5", bits: 64, err: "invalid integer: 1.5"},
+		{in: "", bits: 64, err: "invalid integer: "},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseInt(tt.in, tt.bits)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("ParseInt(%q, %d) error = %v, want %q", tt.in, tt.bits, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseInt(%q, %d) unexpected error: %v", tt.in, tt.bits, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseInt(%q, %d) = %d, want %d", tt.in, tt.bits, got, tt.want)
+		}
+	}
+}
+
+func TestParseUint(t *testing.T) {
+	tests := []struct {
+		in   string
+		bits int
+		want uint64
+		err  string
+	}{
+		{in: "42", bits: 64, want: 42},
+		{in: "08", bits: 8, want: 8},
+		{in: "255", bits: 8, want: 255},
+		{in: "256", bits: 8, err: "unsigned integer out of range for 8 bits: 256"},
+		{in: "65535", bits: 16, want: 65535},
+		{in: "65536", bits: 16, err: "unsigned integer out of range for 16 bits: 65536"},
+		{in: "4294967296", bits: 32, err: "unsigned integer out of range for 32 bits: 4294967296"},
+		{in: "18446744073709551615", bits: 64, want: 18446744073709551615},
+		{in: "18446744073709551616", bits: 64, err: "unsigned integer out of range for 64 bits: 18446744073709551616"},
+		{in: "0xff", bits: 8, want: 255},
+		{in: "0o777", bits: 16, want: 0o777},
+		{in: "0b1111_0000", bits: 8, want: 0xf0},
+		{in: "1_000", bits: 16, want: 1000},
+		{in: "-1", bits: 64, err: "invalid unsigned integer: -1"},
+		{in: "_1", bits: 64, err: "invalid unsigned integer: _1"},
+		{in: "1__0", bits: 64, err: "invalid unsigned integer: 1__0"},
+		{in: "1_", bits: 64, err: "invalid unsigned integer: 1_"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseUint(tt.in, tt.bits)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("ParseUint(%q, %d) error = %v, want %q", tt.in, tt.bits, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseUint(%q, %d) unexpected error: %v", tt.in, tt.bits, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseUint(%q, %d) = %d, want %d", tt.in, tt.bits, got, tt.want)
+		}
+	}
+}
+
+func TestIntLiteral(t *testing.T) {
+	tests := []struct {
+		in     string
+		digits string
+		base   int
+	}{
+		{in: "42", digits: "42", base: 10},
+		{in: "08", digits: "08", base: 10},
+		{in: "1_000", digits: "1000", base: 10},
+		{in: "-1_0", digits: "-10", base: 10},
+		{in: "0x_ff", digits: "0x_ff", base: 0},
+		{in: "-0o7", digits: "-0o7", base: 0},
+		{in: "0B1", digits: "0B1", base: 0},
+		{in: "_1", digits: "_1", base: 10},
+		{in: "1__0", digits: "1__0", base: 10},
+		{in: "1_", digits: "1_", base: 10},
+		{in: "0", digits: "0", base: 10},
+	}
+
+	for _, tt := range tests {
+		digits, base := intLiteral(tt.in)
+		if digits != tt.digits || base != tt.base {
+			t.Errorf("intLiteral(%q) = %q, %d, want %q, %d", tt.in, digits, base, tt.digits, tt.base)
+		}
+	}
+}
+
+func TestGetValue(t *testing.T) {
+	t.Setenv("GETVALUE_TEST", "env")
+	cli := func(s string) *string { return &s }
+
+	tests := []struct {
+		name string
+		cli  *string
+		env  string
+		def  string
+		want string
+	}{
+		{name: "cli", cli: cli("cli"), env: "GETVALUE_TEST", def: "def", want: "cli"},
+		{name: "negated", cli: cli("false"), env: "GETVALUE_TEST", def: "true", want: "false"},
+		{name: "empty cli", cli: cli(""), env: "GETVALUE_TEST", want: ""},
+		{name: "env", env: "GETVALUE_TEST", def: "def", want: "env"},
+		{name: "default", env: "GETVALUE_UNSET", def: "def", want: "def"},
+		{name: "none", want: ""},
+	}
+
+	for _, tt := range tests {
+		got, err := GetValue(tt.cli, tt.env, tt.def, false)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: GetValue() = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestBindSlice(t *testing.T) {
+	tests := []struct {
+		name   string
+		target any
+		values []string
+		want   any
+		err    string
+	}{
+		{name: "ints", target: &[]int{9}, values: []string{"80", "0x1bb"}, want: &[]int{80, 443}},
+		{name: "durations", target: &[]time.Duration{}, values: []string{"1s", "1m30s"}, want: &[]time.Duration{time.Second, 90 * time.Second}},
+		{name: "empty", target: &[]int{9}, values: nil, want: &[]int{}},
+		{name: "invalid int", target: &[]int{}, values: []string{"80", "x"}, err: "element 1: invalid integer: x"},
+		{name: "int out of range", target: &[]int8{}, values: []string{"1", "2", "300"}, err: "element 2: integer out of range for 8 bits: 300"},
+		{name: "invalid duration", target: &[]time.Duration{}, values: []string{"soon"}, err: "element 0: invalid duration: soon"},
+	}
+
+	for _, tt := range tests {
+		err := BindSlice(reflect.ValueOf(tt.target).Elem(), tt.values)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s: BindSlice() error = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: BindSlice() unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.target, tt.want) {
+			t.Errorf("%s: BindSlice() = %v, want %v", tt.name, tt.target, tt.want)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+// Package respfile expands response files, text files holding command-line
+// arguments, referenced as @path among the arguments.
+package respfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxDepth is the maximum nesting of response files.
+const MaxDepth = 10
+
+// errTooDeep reports response files nested beyond MaxDepth.
+var errTooDeep = fmt.Errorf("response files nested too deeply (limit %d)", MaxDepth)
+
+// word is an argument read from a response file.
+type word struct {
+	text string
+	line int
+	// quoted reports whether the word starts with a quoted or escaped
+	// character, in which case a leading @ is literal.
+	quoted bool
+}
+
+// ValueMode tells how the argument following a flag is expanded.
+type ValueMode int
+
+const (
+	// ExpandNext expands the next argument, e.g. after a switch.
+	ExpandNext ValueMode = iota
+	// KeepNext leaves the next argument as is, e.g. after a flag reading
+	// @path values itself. A leading @@ is still unescaped.
+	KeepNext
+	// RejectNext fails when the next argument is a response file, e.g. after
+	// a flag taking a single value, which the file would split.
+	RejectNext
+)
+
+// Expand replaces every @path argument with the arguments read from the file,
+// expanding nested response files up to MaxDepth levels. Nested paths are
+// relative to the file referencing them. A leading @@ escapes a literal @, and
+// arguments after "--", including the ones following a "--" read from a
+// response file, are never expanded.
+//
+// Example:
+//
+//	args, err := respfile.Expand(os.Args[1:])
+func Expand(args []string) ([]string, error) {
+	return ExpandFunc(args, nil)
+}
+
+// ExpandFunc is like Expand, but calls mode with every argument, including
+// the ones read from response files, to know how the argument following it is
+// expanded.
+//
+// Example:
+//
+//	args, err := respfile.ExpandFunc(os.Args[1:], func(arg string) respfile.ValueMode {
+//		if arg == "--body" {
+//			return respfile.KeepNext
+//		}
+//		return respfile.ExpandNext
+//	})
+func ExpandFunc(args []string, mode func(arg string) ValueMode) ([]string, error) {
+	e := &expander{mode: mode}
+	var out []string
+	for _, arg := range args {
+		expanded, err := e.expand(arg, false, "", 0)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, expanded...)
+	}
+	return out, nil
+}
+
+// expander expands arguments, remembering how the next one is handled.
+type expander struct {
+	mode func(arg string) ValueMode
+	prev string    // the last argument seen
+	next ValueMode // how the next argument is handled
+	// terminated reports whether "--" was seen, on the command line or in a
+	// response file, after which every argument is kept as is.
+	terminated bool
+}
+
+// expand returns the arguments an argument expands to. Literal arguments,
+// such as quoted words, are never expanded. Relative paths are resolved
+// against dir, and depth is the nesting of the file holding the argument.
+func (e *expander) expand(arg string, literal bool, dir string, depth int) ([]string, error) {
+	if e.terminated {
+		return []string{arg}, nil
+	}
+	if arg == "--" {
+		e.terminated = true
+		return []string{arg}, nil
+	}
+
+	next, prev := e.next, e.prev
+	e.prev, e.next = arg, ExpandNext
+	if e.mode != nil {
+		e.next = e.mode(arg)
+	}
+
+	if literal {
+		return []string{arg}, nil
+	}
+	if escaped, ok := strings.CutPrefix(arg, "@@"); ok {
+		return []string{"@" + escaped}, nil
+	}
+	path, ok := strings.CutPrefix(arg, "@")
+	if !ok || path == "" || next == KeepNext {
+		return []string{arg}, nil
+	}
+	if next == RejectNext {
+		return nil, fmt.Errorf("cannot expand response file %s as the value of %s (escape it as @%s)", arg, prev, arg)
+	}
+	if depth >= MaxDepth {
+		return nil, errTooDeep
+	}
+	if dir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return e.expandFile(path, depth+1)
+}
+
+// expandFile reads the arguments of a response file at the given depth.
+func (e *expander) expandFile(path string, depth int) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read response file: %w", err)
+	}
+
+	words, err := split(path, string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for _, w := range words {
+		expanded, err := e.expand(w.text, w.quoted, filepath.Dir(path), depth)
+		if err != errTooDeep && errors.Is(err, errTooDeep) {
+			// Already located in the innermost file.
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, w.line, err)
+		}
+		out = append(out, expanded...)
+	}
+	return out, nil
+}
+
+// split splits the content of a response file into words like a POSIX shell:
+// words are separated by whitespace, single quotes keep their content literal,
+// double quotes allow \" \\ and \$ escapes, a backslash outside quotes escapes
+// the next character (a line continuation when it is a newline) and # starts
+// a comment at the beginning of a word. Errors are reported as path:line: message.
+func split(path, data string) ([]word, error) {
+	var words []word
+	var sb strings.Builder
+	inWord := false
+	cur := word{}
+	line := 1
+
+	start := func(quoted bool) {
+		if !inWord {
+			inWord = true
+			cur = word{line: line, quoted: quoted}
+		}
+	}
+	end := func() {
+		if inWord {
+			cur.text = sb.String()
+			words = append(words, cur)
+			sb.Reset()
+			inWord = false
+		}
+	}
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '\n':
+			end()
+			line++
+		case c == ' ' || c == '\t' || c == '\r':
+			end()
+		case c == '#' && !inWord:
+			for i+1 < len(data) && data[i+1] != '\n' {
+				i++
+			}
+		case c == '\\':
+			if i+1 == len(data) {
+				start(false)
+				sb.WriteByte(c)
+				continue
+			}
+			i++
+			if data[i] == '\n' {
+				line++
+				continue
+			}
+			start(true)
+			sb.WriteByte(data[i])
+		case c == '\'':
+			start(true)
+			openLine := line
+			j := strings.IndexByte(data[i+1:], '\'')
+			if j < 0 {
+				return nil, fmt.Errorf("%s:%d: unterminated single quote", path, openLine)
+			}
+			content := data[i+1 : i+1+j]
+			sb.WriteString(content)
+			line += strings.Count(content, "\n")
+			i += j + 1
+		case c == '"':
+			start(true)
+			openLine := line
+			closed := false
+			for i++; i < len(data); i++ {
+				c := data[i]
+				if c == '"' {
+					closed = true
+					break
+				}
+				if c == '\\' && i+1 < len(data) {
+					switch data[i+1] {
+					case '"', '\\', '$', '`':
+						i++
+						c = data[i]
+					case '\n':
+						i++
+						line++
+						continue
+					}
+				}
+				if c == '\n' {
+					line++
+				}
+				sb.WriteByte(c)
+			}
+			if !closed {
+				return nil, fmt.Errorf("%s:%d: unterminated double quote", path, openLine)
+			}
+		default:
+			start(false)
+			sb.WriteByte(c)
+		}
+	}
+	end()
+
+	return words, nil
+}
@@ -0,0 +1,258 @@
+package respfile
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+		err  string
+	}{
+		{
+			name: "words",
+			data: "--region eu\n\t--replicas  3\r\n",
+			want: []string{"--region", "eu", "--replicas", "3"},
+		},
+		{
+			name: "comments",
+			data: "# comment\n--a x # trailing\n--b a#b\n",
+			want: []string{"--a", "x", "--b", "a#b"},
+		},
+		{
+			name: "quoting",
+			data: `--tag "team platform" 'cost "center"' "a \"b\" \$c \d" it\'s ""`,
+			want: []string{"--tag", "team platform", `cost "center"`, `a "b" $c \d`, "it's", ""},
+		},
+		{
+			name: "continuation",
+			data: "--image app \\\n  --replicas 3\n",
+			want: []string{"--image", "app", "--replicas", "3"},
+		},
+		{
+			name: "multi-line quote",
+			data: "\"a\nb\" c\n",
+			want: []string{"a\nb", "c"},
+		},
+		{
+			name: "unterminated double quote",
+			data: "--a x\n--b \"open\n",
+			err:  "args.rsp:2: unterminated double quote",
+		},
+		{
+			name: "unterminated single quote",
+			data: "\n\n'open\nstill\n",
+			err:  "args.rsp:3: unterminated single quote",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, err := split("args.rsp", tt.data)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("split() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("split() error = %v", err)
+			}
+
+			var got []string
+			for _, w := range words {
+				got = append(got, w.text)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("split() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// writeFiles writes the given files to a temporary directory, which becomes
+// the working directory of the test.
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+}
+
+func TestExpand(t *testing.T) {
+	writeFiles(t, map[string]string{
+		"deploy.rsp":      "--region eu\n@conf/common.rsp\n'@literal' @@escaped\n",
+		"conf/common.rsp": "--tag a # nested\n@more.rsp\n",
+		"conf/more.rsp":   "--tag b\n",
+		"empty.rsp":       "# nothing\n",
+		"bad.rsp":         "--a\n@missing.rsp\n",
+		"broken.rsp":      "--a\n\"open\n",
+		"loop.rsp":        "@loop.rsp\n",
+		"tail.rsp":        "-v -- @conf/more.rsp @@x\n",
+		"nested-tail.rsp": "@tail.rsp @deploy.rsp\n",
+	})
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+		err  string
+	}{
+		{
+			name: "nested",
+			args: []string{"deploy", "@deploy.rsp", "--dry-run"},
+			want: []string{"deploy", "--region", "eu", "--tag", "a", "--tag", "b", "@literal", "@escaped", "--dry-run"},
+		},
+		{
+			name: "empty",
+			args: []string{"@empty.rsp", "x"},
+			want: []string{"x"},
+		},
+		{
+			name: "escape and terminator",
+			args: []string{"@@user", "@", "--", "@deploy.rsp"},
+			want: []string{"@user", "@", "--", "@deploy.rsp"},
+		},
+		{
+			name: "terminator in a response file",
+			args: []string{"@tail.rsp", "@deploy.rsp", "@@y"},
+			want: []string{"-v", "--", "@conf/more.rsp", "@@x", "@deploy.rsp", "@@y"},
+		},
+		{
+			name: "terminator in a nested response file",
+			args: []string{"@nested-tail.rsp", "@deploy.rsp"},
+			want: []string{"-v", "--", "@conf/more.rsp", "@@x", "@deploy.rsp", "@deploy.rsp"},
+		},
+		{
+			name: "missing file",
+			args: []string{"@nope.rsp"},
+			err:  "cannot read response file: open nope.rsp:",
+		},
+		{
+			name: "missing nested file",
+			args: []string{"@bad.rsp"},
+			err:  "bad.rsp:2: cannot read response file: open missing.rsp:",
+		},
+		{
+			name: "syntax error",
+			args: []string{"@broken.rsp"},
+			err:  "broken.rsp:2: unterminated double quote",
+		},
+		{
+			name: "too deep",
+			args: []string{"@loop.rsp"},
+			err:  "loop.rsp:1: response files nested too deeply (limit 10)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Expand(tt.args)
+			if tt.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+					t.Fatalf("Expand() error = %v, want prefix %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expand() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandFunc(t *testing.T) {
+	writeFiles(t, map[string]string{
+		"args.rsp":  "--verbose\n",
+		"value.rsp": "--region\n@args.rsp\n",
+		"tail.rsp":  "--body\n",
+	})
+
+	mode := func(arg string) ValueMode {
+		switch arg {
+		case "--body":
+			return KeepNext
+		case "--region":
+			return RejectNext
+		}
+		return ExpandNext
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+		err  string
+	}{
+		{
+			name: "switch",
+			args: []string{"--verbose", "@args.rsp"},
+			want: []string{"--verbose", "--verbose"},
+		},
+		{
+			name: "kept value",
+			args: []string{"--body", "@payload.json", "@args.rsp"},
+			want: []string{"--body", "@payload.json", "--verbose"},
+		},
+		{
+			name: "escaped kept value",
+			args: []string{"--body", "@@payload.json"},
+			want: []string{"--body", "@payload.json"},
+		},
+		{
+			name: "kept value after a response file",
+			args: []string{"@tail.rsp", "@payload.json"},
+			want: []string{"--body", "@payload.json"},
+		},
+		{
+			name: "escaped rejected value",
+			args: []string{"--region", "@@eu"},
+			want: []string{"--region", "@eu"},
+		},
+		{
+			name: "rejected value",
+			args: []string{"--region", "@args.rsp"},
+			err:  "cannot expand response file @args.rsp as the value of --region (escape it as @@args.rsp)",
+		},
+		{
+			name: "rejected value in a response file",
+			args: []string{"@value.rsp"},
+			err:  "value.rsp:2: cannot expand response file @args.rsp as the value of --region (escape it as @@args.rsp)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandFunc(tt.args, mode)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("ExpandFunc() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExpandFunc() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ExpandFunc() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}